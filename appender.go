@@ -1,9 +1,14 @@
 package logger
 
 import (
+	"bytes"
 	"io"
 	"os"
+	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Appender writes log entries to a destination
@@ -13,16 +18,47 @@ type Appender interface {
 	Close() error
 }
 
+// Reopener is implemented by appenders that write to files and can close
+// and reopen them, e.g. after logrotate moved the file away
+type Reopener interface {
+	Reopen() error
+}
+
+// Syncer is implemented by appenders that can commit what they wrote to
+// stable storage, e.g. fsync a file
+type Syncer interface {
+	Sync() error
+}
+
+// Flusher is implemented by appenders that buffer or queue entries
+type Flusher interface {
+	Flush() error
+}
+
 // BaseAppender provides common functionality for appenders
 type BaseAppender struct {
 	name   string
 	layout Layout
 	filter Filter
+	level  atomic.Int32 // entries below are dropped before the filter runs
 	mu     sync.Mutex
 }
 
+// SetLevel drops entries below level, takes effect immediately
+func (b *BaseAppender) SetLevel(level Level) {
+	b.level.Store(int32(level))
+}
+
+// Level returns the appender level, TRACE unless set
+func (b *BaseAppender) Level() Level {
+	return Level(b.level.Load())
+}
+
 // applyFilter checks if entry should be logged
 func (b *BaseAppender) applyFilter(entry *Entry) bool {
+	if entry.Level < b.Level() {
+		return false
+	}
 	if b.filter == nil {
 		return true
 	}
@@ -30,23 +66,47 @@ func (b *BaseAppender) applyFilter(entry *Entry) bool {
 	return result != DENY
 }
 
+// levelAccepts is a side-effect free pre-check used before evaluating lazy
+// arguments: false only when a plain threshold filter would deny level
+func (b *BaseAppender) levelAccepts(level Level) bool {
+	if level < b.Level() {
+		return false
+	}
+	switch f := b.filter.(type) {
+	case *LevelFilter:
+		if f.onMismatch == DENY && (level < f.minLevel || f.maxLevel != OFF && level > f.maxLevel) {
+			return false
+		}
+	case *DynamicThresholdFilter:
+		if f.onMismatch == DENY && f.key == "" && level < f.Level() {
+			return false
+		}
+	}
+	return true
+}
+
 // ConsoleAppender writes to stdout or stderr
 type ConsoleAppender struct {
 	BaseAppender
-	writer io.Writer
-	target string // "stdout" or "stderr"
+	writer  io.Writer
+	target  string // "stdout" or "stderr"
+	color   string // "auto", "always" or "never"
+	colored bool
 }
 
 // NewConsoleAppender creates a console appender writing to stdout
 func NewConsoleAppender() *ConsoleAppender {
-	return &ConsoleAppender{
+	c := &ConsoleAppender{
 		BaseAppender: BaseAppender{
 			name:   "Console",
 			layout: NewTextLayout(),
 		},
 		writer: os.Stdout,
 		target: "stdout",
+		color:  "auto",
 	}
+	c.colored = c.detectColor()
+	return c
 }
 
 // WithName sets the appender name
@@ -75,9 +135,42 @@ func (c *ConsoleAppender) WithTarget(target string) *ConsoleAppender {
 	} else {
 		c.writer = os.Stdout
 	}
+	c.colored = c.detectColor()
 	return c
 }
 
+// WithColor sets when colored output is written: "auto" (the default)
+// only on a terminal and honoring NO_COLOR/FORCE_COLOR, "always" or "never".
+// When enabled, lines of a text layout without colors of its own are
+// colored by level; when disabled, escape codes from ColoredLayout or
+// %highlight are stripped. Other layouts are never altered.
+func (c *ConsoleAppender) WithColor(mode string) *ConsoleAppender {
+	c.color = strings.ToLower(mode)
+	c.colored = c.detectColor()
+	return c
+}
+
+// detectColor decides whether escape codes reach the console
+func (c *ConsoleAppender) detectColor() bool {
+	f, isFile := c.writer.(*os.File)
+	switch c.color {
+	case "always":
+		if isFile {
+			enableColor(f)
+		}
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("FORCE_COLOR"); force != "" && force != "0" && force != "false" {
+		return !isFile || enableColor(f)
+	}
+	return isFile && isTerminal(f) && enableColor(f)
+}
+
 // FilterLevel sets a threshold filter for this appender
 func (c *ConsoleAppender) FilterLevel(level string) *ConsoleAppender {
 	return c.WithFilter(NewThresholdFilter(ParseLevel(level)))
@@ -104,7 +197,22 @@ func (c *ConsoleAppender) Append(entry *Entry) error {
 		return nil
 	}
 
-	data := c.layout.Format(entry)
+	layout := c.layout
+	if colored, ok := layout.(*ColoredLayout); ok && !c.colored {
+		layout = colored.inner
+	}
+	buf := formatEntry(layout, entry)
+	defer freeBuffer(buf)
+
+	data := buf.Bytes()
+	if isTextLayout(layout) {
+		switch {
+		case !c.colored && bytes.IndexByte(data, '\033') >= 0:
+			data = ansiEscapes.ReplaceAll(data, nil)
+		case c.colored && bytes.IndexByte(data, '\033') < 0:
+			data = colorLine(data, levelColors[entry.Level])
+		}
+	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -113,17 +221,120 @@ func (c *ConsoleAppender) Append(entry *Entry) error {
 	return err
 }
 
+// isTextLayout reports whether layout writes human-readable lines, the
+// only output the console colors or strips escape codes from. Structured
+// and binary layouts such as JSON, GELF, CEF, msgpack and proto are
+// written untouched.
+func isTextLayout(layout Layout) bool {
+	switch l := layout.(type) {
+	case *TextLayout, *PatternLayout, *ColoredLayout, *TemplateLayout:
+		return true
+	case *MultilineLayout:
+		return isTextLayout(l.inner)
+	case *TruncateLayout:
+		return isTextLayout(l.inner)
+	}
+	return false
+}
+
+// ansiEscapes matches SGR color sequences
+var ansiEscapes = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// colorLine wraps a formatted line in color, keeping the trailing newline
+// outside so the reset does not leak onto the next line
+func colorLine(data []byte, color string) []byte {
+	if color == "" {
+		return data
+	}
+	line, newline := bytes.CutSuffix(data, []byte("\n"))
+	colored := make([]byte, 0, len(color)+len(data)+len(colorReset))
+	colored = append(colored, color...)
+	colored = append(colored, line...)
+	colored = append(colored, colorReset...)
+	if newline {
+		colored = append(colored, '\n')
+	}
+	return colored
+}
+
 // Close does nothing for console
 func (c *ConsoleAppender) Close() error {
 	return nil
 }
 
+// defaultReopenInterval is how often file appenders check whether their
+// file was moved or removed by an external tool such as logrotate
+const defaultReopenInterval = time.Second
+
+// fileMoved reports whether path no longer refers to the open file
+func fileMoved(file *os.File, path string) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return true
+	}
+	onDisk, err := os.Stat(path)
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(opened, onDisk)
+}
+
+// banner holds the optional header written at the top of each new log file
+// and the footer written before a file is closed or rotated
+type banner struct {
+	header func() string
+	footer func() string
+}
+
+// writeHeader writes the header if the file is empty
+func (b *banner) writeHeader(file *os.File) {
+	if b.header == nil {
+		return
+	}
+	if info, err := file.Stat(); err != nil || info.Size() > 0 {
+		return
+	}
+	file.WriteString(withNewline(b.header()))
+}
+
+// writeFooter writes the footer
+func (b *banner) writeFooter(file *os.File) {
+	if b.footer == nil || file == nil {
+		return
+	}
+	file.WriteString(withNewline(b.footer()))
+}
+
+func withNewline(s string) string {
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		return s + "\n"
+	}
+	return s
+}
+
+// bannerText returns a banner function expanding ${pid}, ${hostname},
+// ${time} and ${file} placeholders in text
+func bannerText(text, filename string) func() string {
+	return func() string {
+		return strings.NewReplacer(
+			"${pid}", pidString,
+			"${hostname}", cachedHostname(),
+			"${time}", time.Now().Format(time.RFC3339),
+			"${file}", filename,
+		).Replace(text)
+	}
+}
+
 // FileAppender writes to a file
 type FileAppender struct {
 	BaseAppender
+	banner
 	file     *os.File
 	filename string
 	append   bool
+
+	reopenInterval time.Duration // 0 disables external rotation checks
+	lastCheck      time.Time
 }
 
 // NewFileAppender creates a file appender
@@ -133,8 +344,9 @@ func NewFileAppender(filename string) *FileAppender {
 			name:   "File",
 			layout: NewTextLayout(),
 		},
-		filename: filename,
-		append:   true,
+		filename:       filename,
+		append:         true,
+		reopenInterval: defaultReopenInterval,
 	}
 }
 
@@ -162,6 +374,35 @@ func (f *FileAppender) WithAppend(append bool) *FileAppender {
 	return f
 }
 
+// WithReopenCheck sets how often to check whether the file was moved away
+// (e.g. by logrotate) and needs reopening, 0 disables the check
+func (f *FileAppender) WithReopenCheck(interval time.Duration) *FileAppender {
+	f.reopenInterval = interval
+	return f
+}
+
+// WithHeader sets a function producing the header of each new file
+func (f *FileAppender) WithHeader(header func() string) *FileAppender {
+	f.header = header
+	return f
+}
+
+// WithFooter sets a function producing the footer written on close
+func (f *FileAppender) WithFooter(footer func() string) *FileAppender {
+	f.footer = footer
+	return f
+}
+
+// Header sets the header text, expanding ${pid}, ${hostname}, ${time} and ${file}
+func (f *FileAppender) Header(text string) *FileAppender {
+	return f.WithHeader(bannerText(text, f.filename))
+}
+
+// Footer sets the footer text, expanding ${pid}, ${hostname}, ${time} and ${file}
+func (f *FileAppender) Footer(text string) *FileAppender {
+	return f.WithFooter(bannerText(text, f.filename))
+}
+
 // open opens the file if not already open
 func (f *FileAppender) open() error {
 	if f.file != nil {
@@ -180,6 +421,24 @@ func (f *FileAppender) open() error {
 		return err
 	}
 	f.file = file
+	f.lastCheck = time.Now()
+	f.writeHeader(file)
+	return nil
+}
+
+// reopen closes the file and opens the path again, always appending
+func (f *FileAppender) reopen() error {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	file, err := os.OpenFile(f.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	f.lastCheck = time.Now()
+	f.writeHeader(file)
 	return nil
 }
 
@@ -193,7 +452,12 @@ func (f *FileAppender) Append(entry *Entry) error {
 	if !f.applyFilter(entry) {
 		return nil
 	}
+	return f.appendUnfiltered(entry)
+}
 
+// appendUnfiltered writes entry regardless of the appender level and
+// filter, see AuditLogger
+func (f *FileAppender) appendUnfiltered(entry *Entry) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
@@ -201,9 +465,46 @@ func (f *FileAppender) Append(entry *Entry) error {
 		return err
 	}
 
-	data := f.layout.Format(entry)
-	_, err := f.file.Write(data)
-	return err
+	// Follow external rotation instead of writing to a moved/deleted file
+	if f.reopenInterval > 0 && time.Since(f.lastCheck) >= f.reopenInterval {
+		f.lastCheck = time.Now()
+		if fileMoved(f.file, f.filename) {
+			if err := f.reopen(); err != nil {
+				return err
+			}
+		}
+	}
+
+	buf := formatEntry(f.layout, entry)
+	defer freeBuffer(buf)
+
+	data := buf.Bytes()
+	if _, err := f.file.Write(data); err != nil {
+		// The file may have been removed underneath us, reopen and retry once
+		if err := f.reopen(); err != nil {
+			return err
+		}
+		_, err = f.file.Write(data)
+		return err
+	}
+	return nil
+}
+
+// Reopen implements Reopener
+func (f *FileAppender) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reopen()
+}
+
+// Sync commits the written entries to stable storage
+func (f *FileAppender) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		return f.file.Sync()
+	}
+	return nil
 }
 
 // Close closes the file
@@ -212,6 +513,7 @@ func (f *FileAppender) Close() error {
 	defer f.mu.Unlock()
 
 	if f.file != nil {
+		f.writeFooter(f.file)
 		err := f.file.Close()
 		f.file = nil
 		return err
@@ -259,15 +561,26 @@ func (w *WriterAppender) Append(entry *Entry) error {
 		return nil
 	}
 
-	data := w.layout.Format(entry)
+	buf := formatEntry(w.layout, entry)
+	defer freeBuffer(buf)
 
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	_, err := w.writer.Write(data)
+	_, err := w.writer.Write(buf.Bytes())
 	return err
 }
 
+// Flush flushes the writer if it buffers, e.g. a *bufio.Writer
+func (w *WriterAppender) Flush() error {
+	if f, ok := w.writer.(interface{ Flush() error }); ok {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return f.Flush()
+	}
+	return nil
+}
+
 // Close does nothing for generic writer
 func (w *WriterAppender) Close() error {
 	if closer, ok := w.writer.(io.Closer); ok {
@@ -1,8 +1,10 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // AsyncAppender wraps an Appender to write logs asynchronously
@@ -11,6 +13,15 @@ type AsyncAppender struct {
 	msgChan  chan *Entry
 	wg       sync.WaitGroup
 	once     sync.Once
+
+	pendingMu sync.Mutex
+	pending   int        // entries queued or being written
+	drained   *sync.Cond // signalled when pending drops to zero
+
+	closeMu sync.RWMutex // held for reading while sending, see Close
+	closed  bool
+	discard atomic.Bool   // set when a shutdown deadline passed
+	dropped atomic.Uint64 // entries discarded or appended after Close
 }
 
 // NewAsyncAppender creates a new AsyncAppender
@@ -23,6 +34,7 @@ func NewAsyncAppender(delegate Appender, bufferSize int) *AsyncAppender {
 		delegate: delegate,
 		msgChan:  make(chan *Entry, bufferSize),
 	}
+	a.drained = sync.NewCond(&a.pendingMu)
 
 	a.wg.Add(1)
 	go a.worker()
@@ -47,31 +59,101 @@ func (a *AsyncAppender) Append(entry *Entry) error {
 	// Optimization: We could use a non-blocking select for "Drop" strategy,
 	// but user asked for "Strongest" which usually implies "Best", and losing logs is bad.
 	// We sticking to blocking to guarantee delivery.
+	a.closeMu.RLock()
+	defer a.closeMu.RUnlock()
+	if a.closed {
+		a.dropped.Add(1)
+		return nil
+	}
+
+	a.pendingMu.Lock()
+	a.pending++
+	a.pendingMu.Unlock()
+	entry.retain() // released by the worker once written
 	a.msgChan <- entry
 	return nil
 }
 
+// Dropped returns how many entries were discarded by a shutdown deadline
+// or appended after Close
+func (a *AsyncAppender) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Flush blocks until every entry appended so far has been written, then
+// flushes the delegate
+func (a *AsyncAppender) Flush() error {
+	a.pendingMu.Lock()
+	for a.pending > 0 {
+		a.drained.Wait()
+	}
+	a.pendingMu.Unlock()
+	if f, ok := a.delegate.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Reopen reopens the delegate if it writes to a file
+func (a *AsyncAppender) Reopen() error {
+	if r, ok := a.delegate.(Reopener); ok {
+		return r.Reopen()
+	}
+	return nil
+}
+
 // Close closes the channel and waits for the worker to finish
 func (a *AsyncAppender) Close() error {
 	var err error
 	a.once.Do(func() {
+		a.closeMu.Lock()
+		a.closed = true
 		close(a.msgChan)
+		a.closeMu.Unlock()
 		a.wg.Wait()
 		err = a.delegate.Close()
 	})
 	return err
 }
 
+// Shutdown writes the queued entries until ctx is done, discards the rest
+// and closes the appender
+func (a *AsyncAppender) Shutdown(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		_ = a.Flush()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		a.discard.Store(true)
+	}
+	return a.Close()
+}
+
 func (a *AsyncAppender) worker() {
 	defer a.wg.Done()
 
 	for entry := range a.msgChan {
-		// We could implement batching here for even more performance if the delegate supports it.
-		// For now, simple forwarding is already huge improvement over sync.
-		err := a.delegate.Append(entry)
-		if err != nil {
-			// Fallback? Print to stderr?
-			fmt.Printf("AsyncAppender: failed to write log: %v\n", err)
+		if a.discard.Load() {
+			a.dropped.Add(1)
+		} else {
+			// We could implement batching here for even more performance if the delegate supports it.
+			// For now, simple forwarding is already huge improvement over sync.
+			err := a.delegate.Append(entry)
+			if err != nil {
+				// Fallback? Print to stderr?
+				fmt.Printf("AsyncAppender: failed to write log: %v\n", err)
+			}
+		}
+		entry.release()
+
+		a.pendingMu.Lock()
+		a.pending--
+		if a.pending == 0 {
+			a.drained.Broadcast()
 		}
+		a.pendingMu.Unlock()
 	}
 }
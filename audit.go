@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AuditMarker marks the entries written by AuditLogger
+const AuditMarker = "AUDIT"
+
+// AuditEvent is a security relevant action. Actor, Action, Resource and
+// Outcome are mandatory.
+type AuditEvent struct {
+	Actor    string // who, e.g. a user or service ID
+	Action   string // what, e.g. "user.delete"
+	Resource string // on what, e.g. "user/42"
+	Outcome  string // e.g. "success", "denied", "failure"
+	Fields   map[string]interface{}
+}
+
+// AuditLogger writes audit events to a dedicated appender, separate from
+// the best effort application log: every event is appended synchronously,
+// bypassing levels, filters, sampling and hooks, including the level and
+// filter of the appender, and synced to stable storage. Log only returns
+// nil once the event is durable, so callers can refuse to proceed
+// otherwise.
+type AuditLogger struct {
+	appender durableAppender
+	name     string
+	mu       sync.Mutex
+}
+
+// durableAppender is implemented by the file appenders, which can write
+// an entry past their own level and filter and sync it to stable storage
+type durableAppender interface {
+	Appender
+	Syncer
+	appendUnfiltered(entry *Entry) error
+}
+
+// NewAuditLogger creates an audit logger writing to appender, an
+// AsyncAppender is bypassed in favor of its delegate. It fails for
+// appenders that cannot sync to stable storage, only FileAppender and
+// RollingFileAppender can.
+func NewAuditLogger(appender Appender) (*AuditLogger, error) {
+	if async, ok := appender.(*AsyncAppender); ok {
+		appender = async.delegate
+	}
+	durable, ok := appender.(durableAppender)
+	if !ok {
+		return nil, fmt.Errorf("audit: appender %T cannot sync to stable storage", appender)
+	}
+	return &AuditLogger{appender: durable, name: "audit"}, nil
+}
+
+// WithName sets the logger name written with every event
+func (a *AuditLogger) WithName(name string) *AuditLogger {
+	a.name = name
+	return a
+}
+
+// Log validates and durably writes an event
+func (a *AuditLogger) Log(event AuditEvent) error {
+	var missing []string
+	for _, f := range []struct{ key, value string }{
+		{"actor", event.Actor},
+		{"action", event.Action},
+		{"resource", event.Resource},
+		{"outcome", event.Outcome},
+	} {
+		if f.value == "" {
+			missing = append(missing, f.key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("audit: missing %v", missing)
+	}
+
+	fields := make(map[string]interface{}, len(event.Fields)+4)
+	addGlobalFields(fields)
+	for k, v := range event.Fields {
+		fields[k] = v
+	}
+	fields["actor"] = event.Actor
+	fields["action"] = event.Action
+	fields["resource"] = event.Resource
+	fields["outcome"] = event.Outcome
+
+	entry := &Entry{
+		Time:     time.Now(),
+		Level:    INFO,
+		Message:  event.Actor + " " + event.Action + " " + event.Resource + ": " + event.Outcome,
+		Template: "audit",
+		Logger:   a.name,
+		Marker:   AuditMarker,
+		Context:  make(map[string]interface{}),
+		Fields:   fields,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.appender.appendUnfiltered(entry); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	var errs []error
+	if f, ok := a.appender.(Flusher); ok {
+		errs = append(errs, f.Flush())
+	}
+	errs = append(errs, a.appender.Sync())
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
+
+// Audit writes an event from its mandatory fields and alternating keys
+// and values, e.g. Audit("alice", "user.delete", "user/42", "success", "ip", ip)
+func (a *AuditLogger) Audit(actor, action, resource, outcome string, keysAndValues ...interface{}) error {
+	fields := make(map[string]interface{}, len(keysAndValues)/2)
+	sweetenFields(fields, keysAndValues)
+	return a.Log(AuditEvent{Actor: actor, Action: action, Resource: resource, Outcome: outcome, Fields: fields})
+}
+
+// Close closes the appender
+func (a *AuditLogger) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.appender.Close()
+}
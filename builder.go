@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,7 +17,19 @@ type Builder struct {
 	name            string
 	level           Level
 	includeLocation bool
+	locationLevel   Level
 	appenders       []Appender
+	filters         []Filter
+	hooks           []Hook
+	exitFunc        func(code int)
+	exitSet         bool
+	stackLevel      Level
+	callerSkip      int
+	sampler         *Sampler
+	globalFields    Fields
+	sequence        bool
+	goroutineID     bool
+	diagnostics     *DiagnosticBuffer
 }
 
 // NewBuilder creates a new logger builder
@@ -24,6 +39,7 @@ func NewBuilder() *Builder {
 		level:           INFO,
 		includeLocation: false,
 		appenders:       make([]Appender, 0),
+		stackLevel:      OFF,
 	}
 }
 
@@ -51,12 +67,91 @@ func (b *Builder) IncludeLocation(include bool) *Builder {
 	return b
 }
 
+// LocationLevel includes caller location only at or above level
+func (b *Builder) LocationLevel(level Level) *Builder {
+	b.includeLocation = level != OFF
+	b.locationLevel = level
+	return b
+}
+
+// WithGlobalFields adds fields attached to every entry of every logger,
+// e.g. service, env or version, see SetGlobalFields. They are installed
+// by Init, Build leaves the process-wide fields alone.
+func (b *Builder) WithGlobalFields(fields Fields) *Builder {
+	if b.globalFields == nil {
+		b.globalFields = make(Fields, len(fields))
+	}
+	for k, v := range fields {
+		b.globalFields[k] = v
+	}
+	return b
+}
+
 // AddAppender adds an appender
 func (b *Builder) AddAppender(appender Appender) *Builder {
 	b.appenders = append(b.appenders, appender)
 	return b
 }
 
+// StackTraceLevel captures stack traces for entries at or above level
+func (b *Builder) StackTraceLevel(level Level) *Builder {
+	b.stackLevel = level
+	return b
+}
+
+// SetExitFunc sets the function Fatal calls, nil makes Fatal return instead of exiting
+func (b *Builder) SetExitFunc(fn func(code int)) *Builder {
+	b.exitFunc = fn
+	b.exitSet = true
+	return b
+}
+
+// AddFilter adds a logger-level filter, evaluated before any appender
+func (b *Builder) AddFilter(filter Filter) *Builder {
+	b.filters = append(b.filters, filter)
+	return b
+}
+
+// AddCallerSkip skips more caller frames when capturing the location,
+// for loggers used behind a wrapper
+func (b *Builder) AddCallerSkip(skip int) *Builder {
+	b.callerSkip += skip
+	return b
+}
+
+// Sampling logs the first entries per message and level in each tick,
+// then every thereafter-th, see NewSampler
+func (b *Builder) Sampling(tick time.Duration, first, thereafter int) *Builder {
+	b.sampler = NewSampler(tick, first, thereafter)
+	return b
+}
+
+// Sequence numbers the entries of the logger, see Logger.SetSequence
+func (b *Builder) Sequence(enabled bool) *Builder {
+	b.sequence = enabled
+	return b
+}
+
+// GoroutineID records the logging goroutine in every entry, see
+// Logger.SetGoroutineID
+func (b *Builder) GoroutineID(enabled bool) *Builder {
+	b.goroutineID = enabled
+	return b
+}
+
+// DiagnosticBuffer keeps up to size entries between level and the logger
+// level, written when an error follows, see Logger.SetDiagnosticBuffer
+func (b *Builder) DiagnosticBuffer(size int, level Level) *Builder {
+	b.diagnostics = NewDiagnosticBuffer(size, level)
+	return b
+}
+
+// AddHook adds a hook run on every entry before filters and appenders
+func (b *Builder) AddHook(hook Hook) *Builder {
+	b.hooks = append(b.hooks, hook)
+	return b
+}
+
 // AddConsole adds a console appender with default settings
 func (b *Builder) AddConsole() *Builder {
 	return b.AddAppender(NewConsoleAppender())
@@ -98,9 +193,12 @@ func (b *Builder) RollingFile(filename string, opts ...func(*RollingFileAppender
 	return b.AddAppender(rf)
 }
 
-// Init builds the logger and sets it as the global logger
+// Init builds the logger and sets it as the global logger, replacing the
+// global fields with those of WithGlobalFields
 func (b *Builder) Init() {
-	globalLogger = b.Build()
+	logger := b.Build()
+	SetGlobalFields(b.globalFields)
+	setGlobal(logger)
 }
 
 // Build constructs the Logger
@@ -108,10 +206,32 @@ func (b *Builder) Build() *Logger {
 	logger := NewLogger(b.name)
 	logger.SetLevel(b.level)
 	logger.SetIncludeLocation(b.includeLocation)
+	if b.locationLevel != TRACE {
+		logger.SetLocationLevel(b.locationLevel)
+	}
 
 	for _, appender := range b.appenders {
 		logger.AddAppender(appender)
 	}
+	for _, filter := range b.filters {
+		logger.AddFilter(filter)
+	}
+	for _, hook := range b.hooks {
+		logger.AddHook(hook)
+	}
+	if b.exitSet {
+		logger.SetExitFunc(b.exitFunc)
+	}
+	logger.SetStackTraceLevel(b.stackLevel)
+	logger.skip.Store(int32(b.callerSkip))
+	if b.sampler != nil {
+		logger.SetSampler(b.sampler)
+	}
+	logger.SetSequence(b.sequence)
+	logger.SetGoroutineID(b.goroutineID)
+	if b.diagnostics != nil {
+		logger.SetDiagnosticBuffer(b.diagnostics)
+	}
 
 	// If no appenders configured, add console as default
 	if len(b.appenders) == 0 {
@@ -121,8 +241,54 @@ func (b *Builder) Build() *Logger {
 	return logger
 }
 
-// Global logger instance
-var globalLogger *Logger
+// Global logger instance, replaced atomically by Init
+var (
+	global        atomic.Pointer[Logger]
+	globalDefault sync.Once
+)
+
+// globalFields are added to every entry, see SetGlobalFields
+var globalFields atomic.Pointer[Fields]
+
+// SetGlobalFields attaches fields to every entry of every logger, fields
+// given at the call site take precedence. nil removes them.
+func SetGlobalFields(fields Fields) {
+	if len(fields) == 0 {
+		globalFields.Store(nil)
+		return
+	}
+	copied := make(Fields, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+	globalFields.Store(&copied)
+}
+
+// addGlobalFields copies the global fields into fields
+func addGlobalFields(fields map[string]interface{}) {
+	if gf := globalFields.Load(); gf != nil {
+		for k, v := range *gf {
+			fields[k] = v
+		}
+	}
+}
+
+// globalLogger returns the global logger. Without Init, a console logger
+// at INFO is installed on first use rather than dropping entries, or a
+// logger holding them for Init to replay, see SetPreInitBuffer.
+func globalLogger() *Logger {
+	if l := global.Load(); l != nil {
+		return l
+	}
+	globalDefault.Do(func() {
+		b := NewBuilder()
+		if preInitSize > 0 {
+			b.AddAppender(newPreInitAppender(preInitSize, preInitWait))
+		}
+		global.CompareAndSwap(nil, b.Build())
+	})
+	return global.Load()
+}
 
 // ============================================================================
 // Configuration Structs (User-Defined Custom Format)
@@ -130,19 +296,61 @@ var globalLogger *Logger
 
 // Configuration defines the log configuration
 type Configuration struct {
-	Level           string           `yaml:"level" json:"level"`                       // DEBUG, INFO, WARN, ERROR, FATAL
-	Format          string           `yaml:"format" json:"format"`                     // text, json
-	Pattern         string           `yaml:"pattern" json:"pattern"`                   // Global pattern
-	Policies        *PoliciesConfig  `yaml:"policies" json:"policies"`                 // Global triggering policies
-	Rollover        *RolloverConfig  `yaml:"rollover" json:"rollover"`                 // Global rollover strategy
-	IncludeLocation bool             `yaml:"include_location" json:"include_location"` // Whether to include caller location
-	Appenders       []AppenderConfig `yaml:"appenders" json:"appenders"`               // List of appenders
+	Level           string                   `yaml:"level" json:"level"`                         // DEBUG, INFO, WARN, ERROR, PANIC, FATAL
+	Format          string                   `yaml:"format" json:"format"`                       // text, json, gelf, stackdriver, xml, syslog, msgpack, proto
+	Pattern         string                   `yaml:"pattern" json:"pattern"`                     // Global pattern
+	Policies        *PoliciesConfig          `yaml:"policies" json:"policies"`                   // Global triggering policies
+	Rollover        *RolloverConfig          `yaml:"rollover" json:"rollover"`                   // Global rollover strategy
+	JSON            *JSONConfig              `yaml:"json" json:"json"`                           // Options for the json format
+	Filters         []map[string]interface{} `yaml:"filters" json:"filters"`                     // Logger-level filters, evaluated before appenders
+	Loggers         map[string]string        `yaml:"loggers" json:"loggers"`                     // Per-logger levels, e.g. github.com/acme/app/db: DEBUG, root: INFO
+	IncludeLocation bool                     `yaml:"include_location" json:"include_location"`   // Whether to include caller location
+	LocationLevel   string                   `yaml:"location_level" json:"location_level"`       // Include caller location only at or above this level, e.g. WARN
+	StackTraceLevel string                   `yaml:"stack_trace_level" json:"stack_trace_level"` // Capture stack traces at or above this level, e.g. ERROR
+	ExitOnFatal     *bool                    `yaml:"exit_on_fatal" json:"exit_on_fatal"`         // Whether Fatal closes appenders and exits (default true)
+	Sampling        *SamplingConfig          `yaml:"sampling" json:"sampling"`                   // Sample repeated messages
+	Diagnostics     *DiagnosticsConfig       `yaml:"diagnostics" json:"diagnostics"`             // Keep recent debug entries, written when an error occurs
+	SlowSQL         string                   `yaml:"slow_sql" json:"slow_sql"`                   // SQL statements at least this slow are logged at WARN, e.g. 200ms
+	SlowAPI         string                   `yaml:"slow_api" json:"slow_api"`                   // API requests at least this slow are logged at WARN, e.g. 1s
+	Fields          map[string]interface{}   `yaml:"fields" json:"fields"`                       // Added to every entry of every logger, e.g. service, env
+	Timezone        string                   `yaml:"timezone" json:"timezone"`                   // Zone of formatted timestamps: UTC, Local or a name such as Asia/Shanghai
+	Sequence        bool                     `yaml:"sequence" json:"sequence"`                   // Number entries to detect drops and reordering
+	GoroutineID     bool                     `yaml:"goroutine_id" json:"goroutine_id"`           // Record the logging goroutine ID in every entry
+	ProcessMetadata bool                     `yaml:"process_metadata" json:"process_metadata"`   // Add hostname, pid and executable fields to every entry
+	Appenders       []AppenderConfig         `yaml:"appenders" json:"appenders"`                 // List of appenders
+}
+
+// SamplingConfig logs the first entries per message and level in each
+// tick, then every thereafter-th
+type SamplingConfig struct {
+	Tick       string `yaml:"tick" json:"tick"` // e.g. 1s (default)
+	First      int    `yaml:"first" json:"first"`
+	Thereafter int    `yaml:"thereafter" json:"thereafter"`
+}
+
+// DiagnosticsConfig keeps the last size entries at or above level that
+// the logger level drops, and writes them before the next error
+type DiagnosticsConfig struct {
+	Size  int    `yaml:"size" json:"size"`   // e.g. 200
+	Level string `yaml:"level" json:"level"` // e.g. DEBUG
+}
+
+// JSONConfig customizes the json format
+type JSONConfig struct {
+	Keys         map[string]string      `yaml:"keys" json:"keys"`                   // Rename standard keys, e.g. message: msg
+	StaticFields map[string]interface{} `yaml:"static_fields" json:"static_fields"` // Added to every entry, e.g. service, env
+	NestFields   bool                   `yaml:"nest_fields" json:"nest_fields"`     // Write fields under a nested "fields" object
+	OmitEmpty    bool                   `yaml:"omit_empty" json:"omit_empty"`       // Skip empty standard keys such as file/line
+	Ordered      bool                   `yaml:"ordered" json:"ordered"`             // Fixed order for standard keys, then sorted
+	ErrorChain   bool                   `yaml:"error_chain" json:"error_chain"`     // Write the error as an object with its wrapped causes
 }
 
 // PoliciesConfig defines triggering policies
 type PoliciesConfig struct {
-	CronTriggeringPolicy      *CronPolicyConfig `yaml:"cron_triggering_policy" json:"cron_triggering_policy"`
-	SizeBasedTriggeringPolicy *SizePolicyConfig `yaml:"size_based_triggering_policy" json:"size_based_triggering_policy"`
+	CronTriggeringPolicy      *CronPolicyConfig    `yaml:"cron_triggering_policy" json:"cron_triggering_policy"`
+	SizeBasedTriggeringPolicy *SizePolicyConfig    `yaml:"size_based_triggering_policy" json:"size_based_triggering_policy"`
+	OnStartupTriggeringPolicy *StartupPolicyConfig `yaml:"on_startup_triggering_policy" json:"on_startup_triggering_policy"`
+	TimeBasedTriggeringPolicy *TimePolicyConfig    `yaml:"time_based_triggering_policy" json:"time_based_triggering_policy"`
 }
 
 // CronPolicyConfig for cron-based triggering
@@ -155,10 +363,25 @@ type SizePolicyConfig struct {
 	Size string `yaml:"size" json:"size"` // e.g. "20MB"
 }
 
+// TimePolicyConfig for calendar-aligned time-based triggering
+type TimePolicyConfig struct {
+	Interval string `yaml:"interval" json:"interval"` // hourly, daily, weekly
+	Modulate *bool  `yaml:"modulate" json:"modulate"` // Align to calendar boundaries (default true)
+	Offset   string `yaml:"offset" json:"offset"`     // e.g. "4h" rolls daily at 04:00
+}
+
+// StartupPolicyConfig for on-startup triggering
+type StartupPolicyConfig struct {
+	MinSize string `yaml:"min_size" json:"min_size"` // e.g. "1KB", defaults to any non-empty file
+	MinAge  string `yaml:"min_age" json:"min_age"`   // e.g. "1d"
+}
+
 // RolloverConfig defines rollover strategy
 type RolloverConfig struct {
-	MaxFile   int    `yaml:"max_file" json:"max_file"`   // Max backup files (0 = no limit)
-	Retention string `yaml:"retention" json:"retention"` // e.g. "30d"
+	MaxFile     int    `yaml:"max_file" json:"max_file"`         // Max backup files (0 = no limit)
+	Retention   string `yaml:"retention" json:"retention"`       // e.g. "30d"
+	MinFree     string `yaml:"min_free" json:"min_free"`         // e.g. "500MB", purge oldest backups below this
+	DropVerbose bool   `yaml:"drop_verbose" json:"drop_verbose"` // Drop DEBUG/TRACE while below min_free
 }
 
 // AppenderConfig defines configuration for an appender
@@ -170,8 +393,13 @@ type AppenderConfig struct {
 	FileName    string                 `yaml:"file_name" json:"file_name"`
 	FilePattern string                 `yaml:"file_pattern" json:"file_pattern"` // e.g. access-%i.log.gz
 	Filter      map[string]interface{} `yaml:"filter" json:"filter"`
-	Async       bool                   `yaml:"async" json:"async"`       // Whether to use async appender
-	Rollover    *RolloverConfig        `yaml:"rollover" json:"rollover"` // Per-appender override
+	Async       bool                   `yaml:"async" json:"async"`             // Whether to use async appender
+	Rollover    *RolloverConfig        `yaml:"rollover" json:"rollover"`       // Per-appender override
+	Header      string                 `yaml:"header" json:"header"`           // Written at the top of each new file, supports ${pid}, ${hostname}, ${time}, ${file}
+	Footer      string                 `yaml:"footer" json:"footer"`           // Written before a file is rotated or closed
+	Color       string                 `yaml:"color" json:"color"`             // Console only: auto (default), always, never
+	Multiline   string                 `yaml:"multiline" json:"multiline"`     // escape or indent embedded newlines
+	MaxMessage  string                 `yaml:"max_message" json:"max_message"` // e.g. "64KB", longer messages are truncated
 }
 
 // ============================================================================
@@ -180,6 +408,18 @@ type AppenderConfig struct {
 
 // Init initializes the global logger with the configuration
 func Init(cfg Configuration) error {
+	// Reject broken patterns and zones before any appender opens a file
+	if err := checkPatterns(cfg); err != nil {
+		return err
+	}
+	var loc *time.Location
+	if cfg.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return fmt.Errorf("timezone: %w", err)
+		}
+	}
+
 	builder := NewBuilder()
 
 	// Set global level
@@ -191,28 +431,96 @@ func Init(cfg Configuration) error {
 	if cfg.IncludeLocation {
 		builder.IncludeLocation(true)
 	}
+	if cfg.LocationLevel != "" {
+		builder.LocationLevel(ParseLevel(cfg.LocationLevel))
+	}
+
+	if cfg.StackTraceLevel != "" {
+		builder.StackTraceLevel(ParseLevel(cfg.StackTraceLevel))
+	}
+	if cfg.ExitOnFatal != nil && !*cfg.ExitOnFatal {
+		builder.SetExitFunc(nil)
+	}
+	if cfg.Sampling != nil {
+		builder.Sampling(parseDuration(cfg.Sampling.Tick), cfg.Sampling.First, cfg.Sampling.Thereafter)
+	}
+
+	// An empty timezone restores local time after an earlier Init
+	SetTimeZone(loc)
+	if cfg.Sequence {
+		builder.Sequence(true)
+	}
+	if cfg.GoroutineID {
+		builder.GoroutineID(true)
+	}
+	if cfg.ProcessMetadata {
+		builder.AddHook(ProcessMetadata())
+	}
+	if len(cfg.Fields) > 0 {
+		builder.WithGlobalFields(cfg.Fields)
+	}
+	if cfg.Diagnostics != nil {
+		builder.DiagnosticBuffer(cfg.Diagnostics.Size, ParseLevel(cfg.Diagnostics.Level))
+	}
+	// Unset thresholds disable slow detection again after an earlier Init
+	SetSlowSQLThreshold(parseDuration(cfg.SlowSQL))
+	SetSlowAPIThreshold(parseDuration(cfg.SlowAPI))
+
+	// Logger-level filters
+	for _, filterCfg := range cfg.Filters {
+		if filter := ParseFilter(filterCfg); filter != nil {
+			builder.AddFilter(filter)
+		}
+	}
 
 	// Determine global layout
 	var globalLayout Layout
 	if cfg.Pattern != "" {
 		globalLayout = NewPatternLayout(cfg.Pattern)
-	} else if strings.ToLower(cfg.Format) == "json" {
-		globalLayout = NewJSONLayout()
 	} else {
-		globalLayout = NewTextLayout()
+		switch strings.ToLower(cfg.Format) {
+		case "json":
+			jsonLayout := NewJSONLayout()
+			if cfg.JSON != nil {
+				jsonLayout.WithKeys(cfg.JSON.Keys).WithStaticFields(cfg.JSON.StaticFields).
+					WithNestedFields(cfg.JSON.NestFields).WithOmitEmpty(cfg.JSON.OmitEmpty).
+					WithOrdered(cfg.JSON.Ordered).WithErrorChain(cfg.JSON.ErrorChain)
+			}
+			globalLayout = jsonLayout
+		case "gelf":
+			globalLayout = NewGELFLayout()
+		case "xml":
+			globalLayout = NewXMLLayout()
+		case "stackdriver", "gcp":
+			globalLayout = NewStackdriverLayout()
+		case "syslog":
+			globalLayout = NewSyslogLayout()
+		case "msgpack":
+			globalLayout = NewMsgpackLayout()
+		case "proto", "protobuf":
+			globalLayout = NewProtoLayout()
+		default:
+			globalLayout = NewTextLayout()
+		}
 	}
 
 	// Parse global rollover config
 	globalMaxFile := 0
 	var globalRetention time.Duration
+	var globalMinFree int64
+	var globalDropVerbose bool
 	if cfg.Rollover != nil {
 		globalMaxFile = cfg.Rollover.MaxFile
 		globalRetention = parseDuration(cfg.Rollover.Retention)
+		globalMinFree = parseSize(cfg.Rollover.MinFree)
+		globalDropVerbose = cfg.Rollover.DropVerbose
 	}
 
 	// Parse global policies
 	var globalSizeBytes int64
 	var globalCronSchedule string
+	var globalStartup *StartupPolicyConfig
+	var globalTime *TimePolicyConfig
 	if cfg.Policies != nil {
 		if cfg.Policies.SizeBasedTriggeringPolicy != nil {
 			globalSizeBytes = parseSize(cfg.Policies.SizeBasedTriggeringPolicy.Size)
@@ -220,6 +528,8 @@ func Init(cfg Configuration) error {
 		if cfg.Policies.CronTriggeringPolicy != nil {
 			globalCronSchedule = cfg.Policies.CronTriggeringPolicy.Schedule
 		}
+		globalStartup = cfg.Policies.OnStartupTriggeringPolicy
+		globalTime = cfg.Policies.TimeBasedTriggeringPolicy
 	}
 
 	// Build appenders
@@ -234,13 +544,16 @@ func Init(cfg Configuration) error {
 			case "console":
 				c := NewConsoleAppender()
 				if appCfg.Pattern != "" {
-					c.WithLayout(NewPatternLayout(appCfg.Pattern))
+					c.WithLayout(truncateLayout(multilineLayout(NewPatternLayout(appCfg.Pattern), appCfg.Multiline), appCfg.MaxMessage))
 				} else {
-					c.WithLayout(globalLayout)
+					c.WithLayout(truncateLayout(multilineLayout(globalLayout, appCfg.Multiline), appCfg.MaxMessage))
 				}
 				if appCfg.Name != "" {
 					c.WithName(appCfg.Name)
 				}
+				if appCfg.Color != "" {
+					c.WithColor(appCfg.Color)
+				}
 				// Construct filter
 				var filter Filter
 				if appCfg.Level != "" {
@@ -259,6 +572,7 @@ func Init(cfg Configuration) error {
 
 				if filter != nil {
 					c.WithFilter(filter)
+					bindFilterOutput(filter, c)
 				}
 				appender = c
 
@@ -269,12 +583,19 @@ func Init(cfg Configuration) error {
 				}
 
 				rf := NewRollingFileAppender(filename)
+				if appCfg.FilePattern != "" {
+					rf.FilePattern(appCfg.FilePattern)
+					// Like log4j2, a pattern without a file name means direct write
+					if appCfg.FileName == "" {
+						rf.DirectWrite(true)
+					}
+				}
 
 				// Layout
 				if appCfg.Pattern != "" {
-					rf.WithLayout(NewPatternLayout(appCfg.Pattern))
+					rf.WithLayout(truncateLayout(multilineLayout(NewPatternLayout(appCfg.Pattern), appCfg.Multiline), appCfg.MaxMessage))
 				} else {
-					rf.WithLayout(globalLayout)
+					rf.WithLayout(truncateLayout(multilineLayout(globalLayout, appCfg.Multiline), appCfg.MaxMessage))
 				}
 
 				// Name
@@ -282,6 +603,14 @@ func Init(cfg Configuration) error {
 					rf.WithName(appCfg.Name)
 				}
 
+				// Header/footer
+				if appCfg.Header != "" {
+					rf.Header(appCfg.Header)
+				}
+				if appCfg.Footer != "" {
+					rf.Footer(appCfg.Footer)
+				}
+
 				// Construct filter
 				var filter Filter
 				if appCfg.Level != "" {
@@ -301,6 +630,7 @@ func Init(cfg Configuration) error {
 
 				if filter != nil {
 					rf.WithFilter(filter)
+					bindFilterOutput(filter, rf)
 				}
 
 				// Policies (use global if not overridden)
@@ -310,10 +640,32 @@ func Init(cfg Configuration) error {
 				if globalCronSchedule != "" {
 					rf.WithPolicy(NewCronBasedPolicy(globalCronSchedule))
 				}
+				if globalTime != nil {
+					timePolicy := NewTimeBasedPolicy(strings.ToLower(globalTime.Interval))
+					if globalTime.Modulate != nil {
+						timePolicy.WithModulate(*globalTime.Modulate)
+					}
+					if globalTime.Offset != "" {
+						timePolicy.WithOffset(parseDuration(globalTime.Offset))
+					}
+					rf.WithPolicy(timePolicy)
+				}
+				if globalStartup != nil {
+					startup := NewOnStartupPolicy()
+					if globalStartup.MinSize != "" {
+						startup.WithMinSize(parseSize(globalStartup.MinSize))
+					}
+					if globalStartup.MinAge != "" {
+						startup.WithMinAge(parseDuration(globalStartup.MinAge))
+					}
+					rf.WithPolicy(startup)
+				}
 
 				// Rollover strategy (per-appender overrides global)
 				maxFile := globalMaxFile
 				retention := globalRetention
+				minFree := globalMinFree
+				dropVerbose := globalDropVerbose
 				if appCfg.Rollover != nil {
 					if appCfg.Rollover.MaxFile > 0 {
 						maxFile = appCfg.Rollover.MaxFile
@@ -321,6 +673,12 @@ func Init(cfg Configuration) error {
 					if appCfg.Rollover.Retention != "" {
 						retention = parseDuration(appCfg.Rollover.Retention)
 					}
+					if appCfg.Rollover.MinFree != "" {
+						minFree = parseSize(appCfg.Rollover.MinFree)
+					}
+					if appCfg.Rollover.DropVerbose {
+						dropVerbose = true
+					}
 				}
 				if maxFile > 0 {
 					rf.WithMaxBackups(maxFile)
@@ -328,6 +686,9 @@ func Init(cfg Configuration) error {
 				if retention > 0 {
 					rf.WithMaxAge(retention)
 				}
+				if minFree > 0 {
+					rf.WithDiskSpacePolicy(NewDiskSpacePolicy(minFree).WithDropVerbose(dropVerbose))
+				}
 
 				appender = rf
 
@@ -347,7 +708,12 @@ func Init(cfg Configuration) error {
 		}
 	}
 
-	globalLogger = builder.Build()
+	builder.Init()
+
+	// Per-logger levels, applied after the root exists so "root" can override the global level
+	for name, level := range cfg.Loggers {
+		SetLevelFor(name, ParseLevel(level))
+	}
 	return nil
 }
 
@@ -355,6 +721,42 @@ func Init(cfg Configuration) error {
 // Helper Functions
 // ============================================================================
 
+// checkPatterns returns the error of the first invalid pattern in cfg
+func checkPatterns(cfg Configuration) error {
+	patterns := []string{cfg.Pattern}
+	for _, appCfg := range cfg.Appenders {
+		patterns = append(patterns, appCfg.Pattern)
+	}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		if err := NewPatternLayout(pattern).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// multilineLayout wraps layout according to the multiline setting
+func multilineLayout(layout Layout, mode string) Layout {
+	switch strings.ToLower(mode) {
+	case MultilineEscape:
+		return NewMultilineLayout(layout).EscapeNewlines()
+	case MultilineIndent:
+		return NewMultilineLayout(layout).IndentContinuation("\t")
+	}
+	return layout
+}
+
+// truncateLayout wraps layout in a TruncateLayout when size is set
+func truncateLayout(layout Layout, size string) Layout {
+	if n := parseSize(size); n > 0 {
+		return NewTruncateLayout(layout, int(n))
+	}
+	return layout
+}
+
 // parseSize parses size string like "20MB" to int64 bytes
 func parseSize(s string) int64 {
 	s = strings.ToUpper(strings.TrimSpace(s))
@@ -375,11 +777,11 @@ func parseSize(s string) int64 {
 	return val
 }
 
-// parseDuration parses duration like "7d", "30d"
+// parseDuration parses duration like "7d", "30d" or Go durations like "4h"
 func parseDuration(s string) time.Duration {
-	s = strings.ToUpper(strings.TrimSpace(s))
-	if strings.HasSuffix(s, "D") {
-		daysStr := strings.TrimSuffix(s, "D")
+	s = strings.ToLower(strings.TrimSpace(s))
+	if strings.HasSuffix(s, "d") {
+		daysStr := strings.TrimSuffix(s, "d")
 		var days int
 		fmt.Sscanf(daysStr, "%d", &days)
 		return time.Duration(days) * 24 * time.Hour
@@ -392,80 +794,231 @@ func parseDuration(s string) time.Duration {
 // Package-level logging functions
 // ============================================================================
 
-func GetLogger() interface{} {
-	return globalLogger
+// IsEnabled checks if a level is enabled on the global logger
+func IsEnabled(level Level) bool {
+	return globalLogger().IsEnabled(level)
 }
 
-func Trace(format string, args ...interface{}) {
-	if globalLogger != nil {
-		globalLogger.Trace(format, args...)
-	}
-}
+func IsInfoEnabled() bool { return IsEnabled(INFO) }
 
-func Debug(format string, args ...interface{}) {
-	if globalLogger != nil {
-		globalLogger.Debug(format, args...)
-	}
+func IsWarnEnabled() bool { return IsEnabled(WARN) }
+
+func IsErrorEnabled() bool { return IsEnabled(ERROR) }
+
+// Check returns a guard for the global logger, nil when level is disabled
+func Check(level Level) *CheckedEntry {
+	return globalLogger().Check(level)
 }
 
 func Info(format string, args ...interface{}) {
-	if globalLogger != nil {
-		globalLogger.Info(format, args...)
-	}
+	globalLogger().Info(format, args...)
 }
 
 func Warn(format string, args ...interface{}) {
-	if globalLogger != nil {
-		globalLogger.Warn(format, args...)
-	}
+	globalLogger().Warn(format, args...)
 }
 
 func Error(format string, args ...interface{}) {
-	if globalLogger != nil {
-		globalLogger.Error(format, args...)
-	}
+	globalLogger().Error(format, args...)
+}
+
+func Panic(format string, args ...interface{}) {
+	globalLogger().Panic(format, args...)
+}
+
+// Panicf is Panic, for code written against logrus or zap's sugared logger
+func Panicf(format string, args ...interface{}) {
+	globalLogger().Panicf(format, args...)
+}
+
+func ErrorE(err error, format string, args ...interface{}) {
+	globalLogger().ErrorE(err, format, args...)
 }
 
 func Fatal(format string, args ...interface{}) {
-	if globalLogger != nil {
-		globalLogger.Fatal(format, args...)
-	}
+	globalLogger().Fatal(format, args...)
+}
+
+func Log(level Level, msg string, fields ...Field) {
+	globalLogger().Log(level, msg, fields...)
+}
+
+func Infow(msg string, keysAndValues ...interface{}) {
+	globalLogger().Infow(msg, keysAndValues...)
+}
+
+func Warnw(msg string, keysAndValues ...interface{}) {
+	globalLogger().Warnw(msg, keysAndValues...)
+}
+
+func Errorw(msg string, keysAndValues ...interface{}) {
+	globalLogger().Errorw(msg, keysAndValues...)
+}
+
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	globalLogger().Fatalw(msg, keysAndValues...)
+}
+
+func Infoln(args ...interface{}) {
+	globalLogger().Infoln(args...)
+}
+
+func Warnln(args ...interface{}) {
+	globalLogger().Warnln(args...)
+}
+
+func Errorln(args ...interface{}) {
+	globalLogger().Errorln(args...)
+}
+
+func Fatalln(args ...interface{}) {
+	globalLogger().Fatalln(args...)
+}
+
+// Print, Printf and Println log at INFO level, for code written against
+// the standard library logger
+func Print(args ...interface{}) {
+	globalLogger().Print(args...)
+}
+
+func Printf(format string, args ...interface{}) {
+	globalLogger().Printf(format, args...)
+}
+
+func Println(args ...interface{}) {
+	globalLogger().Println(args...)
+}
+
+// Every limits the global logger to one entry per d from the calling line
+func Every(d time.Duration) *LimitedLogger {
+	return globalLogger().Every(d)
+}
+
+// Once limits the global logger to the first entry from the calling line
+func Once() *LimitedLogger {
+	return globalLogger().Once()
+}
+
+// TimeTrack times a function with the global logger, see Logger.TimeTrack
+func TimeTrack(name string) func() {
+	return globalLogger().TimeTrack(name)
+}
+
+// StartStopwatch starts a stopwatch logging to the global logger
+func StartStopwatch(name string) *Stopwatch {
+	return globalLogger().StartStopwatch(name)
+}
+
+// Event writes an analytics event with the global logger
+func Event(name string, fields Fields) {
+	globalLogger().Event(name, fields)
+}
+
+// Count writes a counter event with the global logger
+func Count(name string, delta int64) {
+	globalLogger().Count(name, delta)
 }
 
 func WithMarker(marker string) *MarkerLogger {
-	if globalLogger != nil {
-		return globalLogger.WithMarker(marker)
+	return globalLogger().WithMarker(marker)
+}
+
+// Flush drains the asynchronous appenders of the global logger and flushes
+// buffered writers: defer logger.Flush() in main
+func Flush() error {
+	return globalLogger().Flush()
+}
+
+// Shutdown writes what the global logger has queued or buffered and closes
+// its appenders, see Logger.Shutdown
+func Shutdown(ctx context.Context) error {
+	return globalLogger().Shutdown(ctx)
+}
+
+// SetLevel changes the level of the global logger, effective immediately
+func SetLevel(level Level) {
+	globalLogger().SetLevel(level)
+}
+
+// SetAppenderLevel changes the level of an appender of the global logger
+// by name, reporting whether it was found
+func SetAppenderLevel(name string, level Level) bool {
+	appender := globalLogger().GetAppender(name)
+	if async, ok := appender.(*AsyncAppender); ok {
+		appender = async.delegate
 	}
-	return nil
+	if a, ok := appender.(interface{ SetLevel(Level) }); ok {
+		a.SetLevel(level)
+		return true
+	}
+	return false
 }
 
 func WithContext(key string, value interface{}) *Logger {
-	if globalLogger != nil {
-		return globalLogger.WithContext(key, value)
-	}
-	return nil
+	return globalLogger().WithContext(key, value)
+}
+
+var (
+	slowSQLThreshold atomic.Int64
+	slowAPIThreshold atomic.Int64
+)
+
+// SetSlowSQLThreshold logs statements taking at least d at WARN, 0 disables
+func SetSlowSQLThreshold(d time.Duration) {
+	slowSQLThreshold.Store(int64(d))
+}
+
+// SetSlowAPIThreshold logs requests taking at least d at WARN, 0 disables
+func SetSlowAPIThreshold(d time.Duration) {
+	slowAPIThreshold.Store(int64(d))
+}
+
+// isSlow reports whether duration reached a threshold set to non-zero
+func isSlow(threshold *atomic.Int64, duration time.Duration) bool {
+	t := threshold.Load()
+	return t > 0 && int64(duration) >= t
 }
 
+// SQL logs a statement under the SQL marker as "[12ms] [rows:3] SELECT ...",
+// with sql, duration_ms and rows fields, at DEBUG or at WARN when it
+// reached the slow threshold
 func SQL(sql string, duration time.Duration, rows int64) {
-	if globalLogger != nil {
-		globalLogger.WithMarker("SQL").Debug("[%dms] [rows:%d] %s", duration.Milliseconds(), rows, sql)
-	}
+	SQLWithError(sql, duration, rows, false)
 }
 
+// SQLWithError is SQL logging failed statements at ERROR
 func SQLWithError(sql string, duration time.Duration, rows int64, isError bool) {
-	if globalLogger != nil {
-		if isError {
-			globalLogger.WithMarker("SQL").Error("[%dms] [rows:%d] %s", duration.Milliseconds(), rows, sql)
-		} else {
-			globalLogger.WithMarker("SQL").Debug("[%dms] [rows:%d] %s", duration.Milliseconds(), rows, sql)
-		}
+	level := DEBUG
+	if isError {
+		level = ERROR
+	} else if isSlow(&slowSQLThreshold, duration) {
+		level = WARN
+	}
+	logger := globalLogger()
+	if !logger.accepts(level) {
+		return
 	}
+	msg := fmt.Sprintf("[%dms] [rows:%d] %s", duration.Milliseconds(), rows, sql)
+	logger.WithMarker("SQL").Log(level, msg,
+		String("sql", sql), Int64("duration_ms", duration.Milliseconds()), Int64("rows", rows))
 }
 
+// API logs a request under the API marker as "[8ms] [200] 10.0.0.1 GET
+// /users", with method, path, status, client_ip and duration_ms fields,
+// at INFO or at WARN when it reached the slow threshold
 func API(method, path, clientIP string, statusCode int, duration time.Duration) {
-	if globalLogger != nil {
-		globalLogger.WithMarker("API").Info("[%dms] [%d] %s %s %s", duration.Milliseconds(), statusCode, clientIP, method, path)
+	level := INFO
+	if isSlow(&slowAPIThreshold, duration) {
+		level = WARN
 	}
+	logger := globalLogger()
+	if !logger.accepts(level) {
+		return
+	}
+	msg := fmt.Sprintf("[%dms] [%d] %s %s %s", duration.Milliseconds(), statusCode, clientIP, method, path)
+	logger.WithMarker("API").Log(level, msg,
+		String("method", method), String("path", path), Int("status", statusCode),
+		String("client_ip", clientIP), Int64("duration_ms", duration.Milliseconds()))
 }
 
 func LogHTTPRequest(statusCode int, method, path string, latency time.Duration, clientIP string) {
@@ -474,26 +1027,21 @@ func LogHTTPRequest(statusCode int, method, path string, latency time.Duration,
 
 // WithFields adds fields to the global logger
 func WithFields(fields map[string]interface{}) *FieldLogger {
-	if globalLogger != nil {
-		return globalLogger.WithFields(fields)
-	}
-	// Return a dummy/safe logger if globalLogger is nil?
-	// Or panic/return nil. Existing methods return nil.
-	return nil
+	return globalLogger().WithFields(fields)
 }
 
 // WithField adds a single field
 func WithField(key string, value interface{}) *FieldLogger {
-	if globalLogger != nil {
-		return globalLogger.WithFields(map[string]interface{}{key: value})
-	}
-	return nil
+	return globalLogger().WithFields(map[string]interface{}{key: value})
+}
+
+// WithCode sets an event code on the global logger
+func WithCode(code string) *FieldLogger {
+	return globalLogger().WithCode(code)
 }
 
-// WithError adds an error field
+// WithError logs with error on the global logger, rendered with its wrapped
+// causes and stack traces
 func WithError(err error) *FieldLogger {
-	if globalLogger != nil {
-		return globalLogger.WithFields(map[string]interface{}{"error": err})
-	}
-	return nil
+	return globalLogger().WithError(err)
 }
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package logger
+
+import "os"
+
+// isTerminal reports whether f is a character device such as a TTY
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// enableColor is a no-op, terminals render ANSI escapes natively
+func enableColor(f *os.File) bool {
+	return true
+}
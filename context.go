@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// ContextExtractor pulls values out of a context.Context, they are added
+// to the MDC context of every entry logged through WithCtx
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+// AddContextExtractor registers an extractor, registry loggers also use
+// the extractors of their ancestors
+func (l *Logger) AddContextExtractor(extractor ContextExtractor) {
+	l.configure(func(c *loggerConfig) {
+		c.extractors = append(slices.Clip(c.extractors), extractor)
+	})
+}
+
+// extractContext runs the extractors of l and its ancestors, nearest last
+// so it wins on conflicting keys
+func (l *Logger) extractContext(ctx context.Context, into map[string]interface{}) {
+	if ctx == nil {
+		return
+	}
+	if l.named {
+		if p := l.parentLogger(); p != nil {
+			p.extractContext(ctx, into)
+		}
+	}
+	for _, extract := range l.settings().extractors {
+		for k, v := range extract(ctx) {
+			into[k] = v
+		}
+	}
+}
+
+// ContextValue extracts ctx.Value(key) under name, e.g.
+// AddContextExtractor(ContextValue("request_id", requestIDKey{}))
+func ContextValue(name string, key interface{}) ContextExtractor {
+	return func(ctx context.Context) map[string]interface{} {
+		if v := ctx.Value(key); v != nil {
+			return map[string]interface{}{name: v}
+		}
+		return nil
+	}
+}
+
+// ContextDeadline extracts the time left until the context deadline under name
+func ContextDeadline(name string) ContextExtractor {
+	return func(ctx context.Context) map[string]interface{} {
+		if deadline, ok := ctx.Deadline(); ok {
+			return map[string]interface{}{name: time.Until(deadline).Round(time.Millisecond).String()}
+		}
+		return nil
+	}
+}
+
+// SpanContextFunc returns the active trace span of ctx, ok is false when
+// there is none. traceFlags is rendered as two hex digits like W3C traceparent.
+type SpanContextFunc func(ctx context.Context) (traceID, spanID string, traceFlags byte, ok bool)
+
+// traceContextKeys are the MDC keys filled from the active span
+var traceContextKeys = []string{"trace_id", "span_id", "trace_flags"}
+
+var spanContextFunc atomic.Pointer[SpanContextFunc]
+
+// SetSpanContextFunc enables trace correlation: entries logged through
+// WithCtx or Ctx get trace_id, span_id and trace_flags in their context,
+// available as %X{trace_id} and written top level by JSONLayout. The
+// package has no dependencies, wire OpenTelemetry in once at startup:
+//
+//	logger.SetSpanContextFunc(func(ctx context.Context) (string, string, byte, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.TraceID().String(), sc.SpanID().String(), byte(sc.TraceFlags()), sc.IsValid()
+//	})
+//
+// nil disables it
+func SetSpanContextFunc(fn SpanContextFunc) {
+	if fn == nil {
+		spanContextFunc.Store(nil)
+		return
+	}
+	spanContextFunc.Store(&fn)
+}
+
+// extractSpanContext adds the active span of ctx to into
+func extractSpanContext(ctx context.Context, into map[string]interface{}) {
+	fn := spanContextFunc.Load()
+	if fn == nil || ctx == nil {
+		return
+	}
+	traceID, spanID, traceFlags, ok := (*fn)(ctx)
+	if !ok {
+		return
+	}
+	into["trace_id"] = traceID
+	into["span_id"] = spanID
+	into["trace_flags"] = fmt.Sprintf("%02x", traceFlags)
+}
+
+type loggerContextKey struct{}
+
+// NewContext returns a copy of ctx carrying l, retrieve it with FromContext
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the logger stored by NewContext, or the global
+// logger when ctx carries none
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return globalLogger()
+}
+
+// Ctx returns the logger stored in ctx bound to ctx, so the context
+// extractors apply: logger.Ctx(ctx).Info("handled")
+func Ctx(ctx context.Context) *ContextLogger {
+	return FromContext(ctx).WithCtx(ctx)
+}
+
+// ContextLogger logs with the values the context extractors and the span
+// context func find in its context.Context
+type ContextLogger struct {
+	FieldLogger
+}
+
+func (l *Logger) WithCtx(ctx context.Context) *ContextLogger {
+	return &ContextLogger{FieldLogger{logger: l, ctx: ctx}}
+}
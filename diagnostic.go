@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+// DiagnosticBuffer keeps the most recent entries below the logger level,
+// down to a level of its own, and writes them right before the next entry
+// at ERROR or above. Failures come with their debug context without
+// paying for debug output on the happy path:
+//
+//	log.SetDiagnosticBuffer(logger.NewDiagnosticBuffer(200, logger.DEBUG))
+//
+// or, to keep requests apart, one buffer per request:
+//
+//	ctx = logger.WithDiagnosticBuffer(ctx, logger.NewDiagnosticBuffer(100, logger.DEBUG))
+//	logger.Ctx(ctx).Debug("cache miss for %s", key) // kept
+//	logger.Ctx(ctx).Error("load failed: %v", err)   // writes the kept entry, then this one
+type DiagnosticBuffer struct {
+	level   Level
+	mu      sync.Mutex
+	entries []*Entry // ring, entries[next] is the oldest once full
+	next    int
+	full    bool
+}
+
+// NewDiagnosticBuffer keeps up to size entries at or above level
+func NewDiagnosticBuffer(size int, level Level) *DiagnosticBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &DiagnosticBuffer{level: level, entries: make([]*Entry, size)}
+}
+
+// keeps reports whether entries at level go into the buffer, false for nil
+func (b *DiagnosticBuffer) keeps(level Level) bool {
+	return b != nil && level >= b.level
+}
+
+// keep stores a copy of entry, replacing the oldest when full
+func (b *DiagnosticBuffer) keep(entry *Entry) {
+	kept := cloneEntry(entry)
+	b.mu.Lock()
+	b.entries[b.next] = kept
+	b.next++
+	if b.next == len(b.entries) {
+		b.next, b.full = 0, true
+	}
+	b.mu.Unlock()
+}
+
+// drain returns the kept entries, oldest first, and empties the buffer
+func (b *DiagnosticBuffer) drain() []*Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var kept []*Entry
+	if b.full {
+		kept = append(kept, b.entries[b.next:]...)
+	}
+	kept = append(kept, b.entries[:b.next]...)
+	clear(b.entries)
+	b.next, b.full = 0, false
+	return kept
+}
+
+// SetDiagnosticBuffer keeps entries below the level of l in b until an
+// error occurs, nil disables it. Children from Named and Clone share it.
+func (l *Logger) SetDiagnosticBuffer(b *DiagnosticBuffer) {
+	l.diagnostics.Store(b)
+}
+
+// accepts reports whether entries at level are written or kept in the
+// diagnostic buffer
+func (l *Logger) accepts(level Level) bool {
+	if compiledOut(level) {
+		return false
+	}
+	return l.IsEnabled(level) || l.diagnostics.Load().keeps(level)
+}
+
+type diagnosticKey struct{}
+
+// WithDiagnosticBuffer returns a copy of ctx carrying b, used instead of
+// the logger's buffer by the ContextLogger of ctx, see Ctx
+func WithDiagnosticBuffer(ctx context.Context, b *DiagnosticBuffer) context.Context {
+	return context.WithValue(ctx, diagnosticKey{}, b)
+}
+
+// diagnosticBufferFrom returns the buffer carried by ctx, if any
+func diagnosticBufferFrom(ctx context.Context) *DiagnosticBuffer {
+	b, _ := ctx.Value(diagnosticKey{}).(*DiagnosticBuffer)
+	return b
+}
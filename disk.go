@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// DiskSpacePolicy guards the log volume against filling up. When free
+// space drops below the threshold, the oldest backups are purged until
+// enough space is reclaimed, and verbose entries can optionally be dropped.
+type DiskSpacePolicy struct {
+	minFree     int64         // in bytes
+	interval    time.Duration // how often free space is sampled
+	dropVerbose bool          // drop DEBUG/TRACE while space is low
+
+	lastCheck time.Time
+	low       atomic.Bool
+}
+
+// NewDiskSpacePolicy creates a policy keeping at least minFree bytes available
+func NewDiskSpacePolicy(minFree int64) *DiskSpacePolicy {
+	return &DiskSpacePolicy{
+		minFree:  minFree,
+		interval: 10 * time.Second,
+	}
+}
+
+// WithCheckInterval sets how often free space is sampled
+func (p *DiskSpacePolicy) WithCheckInterval(interval time.Duration) *DiskSpacePolicy {
+	p.interval = interval
+	return p
+}
+
+// WithDropVerbose drops DEBUG/TRACE entries while space is low
+func (p *DiskSpacePolicy) WithDropVerbose(drop bool) *DiskSpacePolicy {
+	p.dropVerbose = drop
+	return p
+}
+
+// IsLow reports whether the last sample was below the threshold
+func (p *DiskSpacePolicy) IsLow() bool {
+	return p.low.Load()
+}
+
+// WithDiskSpacePolicy sets the disk space guard for this appender
+func (r *RollingFileAppender) WithDiskSpacePolicy(policy *DiskSpacePolicy) *RollingFileAppender {
+	r.diskPolicy = policy
+	return r
+}
+
+// MinFreeSpace keeps at least the given space (e.g. "500MB") free on the log volume
+func (r *RollingFileAppender) MinFreeSpace(sizeStr string) *RollingFileAppender {
+	return r.WithDiskSpacePolicy(NewDiskSpacePolicy(parseSize(sizeStr)))
+}
+
+// checkDiskSpace samples free space and schedules a purge when it becomes low
+// It returns false if the entry should be dropped
+func (r *RollingFileAppender) checkDiskSpace(entry *Entry) bool {
+	p := r.diskPolicy
+	if time.Since(p.lastCheck) >= p.interval {
+		p.lastCheck = time.Now()
+
+		dir := filepath.Dir(r.currentPath)
+		free, err := freeSpace(dir)
+		low := err == nil && free < p.minFree
+		if wasLow := p.low.Swap(low); low && !wasLow {
+			set, exclude, minFree := r.backups(), r.currentPath, p.minFree
+			r.housekeep(func() {
+				set.purge(dir, exclude, minFree)
+			})
+		}
+	}
+
+	return !(p.low.Load() && p.dropVerbose && entry.Level < INFO)
+}
+
+// purge removes the oldest backups until minFree bytes are available
+func (s backupSet) purge(dir, exclude string, minFree int64) {
+	for _, b := range s.list(exclude) {
+		if free, err := freeSpace(dir); err != nil || free >= minFree {
+			return
+		}
+		os.Remove(b.path)
+	}
+}
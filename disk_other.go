@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !windows
+
+package logger
+
+import "errors"
+
+// freeSpace is not supported on this platform, the disk policy stays idle
+func freeSpace(dir string) (int64, error) {
+	return 0, errors.New("free space check not supported")
+}
@@ -0,0 +1,20 @@
+package logger
+
+// EventMarker marks the entries written by Event and Count
+const EventMarker = "EVENT"
+
+// Event writes a structured analytics event at INFO: the message and the
+// reserved "event" field hold name, the other fields are added as they are
+func (l *Logger) Event(name string, fields Fields) {
+	keysAndValues := make([]interface{}, 0, 2*len(fields)+2)
+	for k, v := range fields {
+		keysAndValues = append(keysAndValues, k, v)
+	}
+	keysAndValues = append(keysAndValues, "event", name)
+	l.logw(INFO, EventMarker, name, keysAndValues)
+}
+
+// Count writes a counter event, delta is held in the reserved "count" field
+func (l *Logger) Count(name string, delta int64) {
+	l.logw(INFO, EventMarker, name, []interface{}{"event", name, "count", delta})
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType tells how a Field's value is stored
+type FieldType uint8
+
+const (
+	AnyType FieldType = iota
+	StringType
+	IntType
+	UintType
+	FloatType
+	BoolType
+	DurationType
+	TimeType
+	ErrorType
+)
+
+// Field is a strongly typed key-value pair. Scalars are stored without
+// boxing, so logging them through Logger.Log allocates neither interfaces
+// nor a Fields map:
+//
+//	log.Log(INFO, "request done", logger.String("path", p), logger.Int("status", 200), logger.Duration("took", d))
+type Field struct {
+	Key       string
+	Type      FieldType
+	Integer   int64
+	String    string
+	Interface interface{}
+}
+
+// String creates a string field
+func String(key, value string) Field {
+	return Field{Key: key, Type: StringType, String: value}
+}
+
+// Int creates an int field
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: IntType, Integer: int64(value)}
+}
+
+// Int64 creates an int64 field
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: IntType, Integer: value}
+}
+
+// Uint64 creates a uint64 field
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Type: UintType, Integer: int64(value)}
+}
+
+// Float64 creates a float64 field
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: FloatType, Integer: int64(math.Float64bits(value))}
+}
+
+// Bool creates a bool field
+func Bool(key string, value bool) Field {
+	var i int64
+	if value {
+		i = 1
+	}
+	return Field{Key: key, Type: BoolType, Integer: i}
+}
+
+// Duration creates a time.Duration field
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// Time creates a time.Time field
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, Interface: value}
+}
+
+// Err creates an "error" field, nil errors are skipped
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr creates an error field under key, nil errors are skipped
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Field{Type: ErrorType}
+	}
+	return Field{Key: key, Type: ErrorType, Interface: err}
+}
+
+// Any creates a field of any type
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Type: AnyType, Interface: value}
+}
+
+// Value returns the field value as an interface
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.String
+	case IntType:
+		return f.Integer
+	case UintType:
+		return uint64(f.Integer)
+	case FloatType:
+		return math.Float64frombits(uint64(f.Integer))
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return err.Error()
+		}
+		return nil
+	}
+	return f.Interface
+}
+
+// appendValue writes the value as text, without boxing scalars
+func (f Field) appendValue(buf *bytes.Buffer) {
+	var scratch [32]byte
+	switch f.Type {
+	case StringType:
+		buf.WriteString(quoteIfNeeded(f.String))
+	case IntType:
+		buf.Write(strconv.AppendInt(scratch[:0], f.Integer, 10))
+	case UintType:
+		buf.Write(strconv.AppendUint(scratch[:0], uint64(f.Integer), 10))
+	case FloatType:
+		buf.Write(strconv.AppendFloat(scratch[:0], math.Float64frombits(uint64(f.Integer)), 'g', -1, 64))
+	case BoolType:
+		buf.Write(strconv.AppendBool(scratch[:0], f.Integer == 1))
+	case DurationType:
+		buf.WriteString(time.Duration(f.Integer).String())
+	case TimeType:
+		if t, ok := f.Interface.(time.Time); ok {
+			buf.Write(t.AppendFormat(scratch[:0], time.RFC3339Nano))
+		}
+	default:
+		buf.WriteString(quoteIfNeeded(fmt.Sprint(f.Value())))
+	}
+}
+
+// quoteIfNeeded quotes values that would be ambiguous in key=value output
+func quoteIfNeeded(v string) string {
+	if v == "" || strings.ContainsAny(v, " \t\r\n\"=") {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// writeTypedFields writes typed fields as key=value pairs in order,
+// separated by spaces, prefixed by a space when sep is set
+func writeTypedFields(buf *bytes.Buffer, fields []Field, sep bool) {
+	for _, f := range fields {
+		if f.Key == "" {
+			continue
+		}
+		if sep {
+			buf.WriteByte(' ')
+		}
+		sep = true
+		buf.WriteString(f.Key)
+		buf.WriteByte('=')
+		f.appendValue(buf)
+	}
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilePattern describes how backup files are named on rollover
+// Supported tokens:
+//
+//	%i         - rollover index
+//	%d{layout} - date/time (Go time format, defaults to 2006-01-02)
+//
+// A pattern ending in ".gz" compresses backups with gzip.
+// Example: "app-%d{2006-01-02}-%i.log.gz"
+type FilePattern struct {
+	pattern string
+	dir     string
+	parts   []filePatternPart
+	regex   *regexp.Regexp
+}
+
+type filePatternPart struct {
+	literal string
+	token   byte // 'i' or 'd'
+	layout  string
+}
+
+var filePatternRegex = regexp.MustCompile(`%([id])(?:\{([^}]+)\})?`)
+
+// NewFilePattern parses a file pattern
+func NewFilePattern(pattern string) *FilePattern {
+	p := &FilePattern{
+		pattern: pattern,
+		dir:     filepath.Dir(pattern),
+	}
+	p.parse(filepath.Base(pattern))
+	return p
+}
+
+func (p *FilePattern) parse(s string) {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for {
+		loc := filePatternRegex.FindStringSubmatchIndex(s)
+		if loc == nil {
+			if len(s) > 0 {
+				p.parts = append(p.parts, filePatternPart{literal: s})
+				expr.WriteString(regexp.QuoteMeta(s))
+			}
+			break
+		}
+
+		if loc[0] > 0 {
+			p.parts = append(p.parts, filePatternPart{literal: s[:loc[0]]})
+			expr.WriteString(regexp.QuoteMeta(s[:loc[0]]))
+		}
+
+		part := filePatternPart{token: s[loc[2]]}
+		if part.token == 'd' {
+			part.layout = "2006-01-02"
+			if loc[4] >= 0 {
+				part.layout = s[loc[4]:loc[5]]
+			}
+			expr.WriteString("(.+?)")
+		} else {
+			expr.WriteString(`(\d+)`)
+		}
+		p.parts = append(p.parts, part)
+
+		s = s[loc[1]:]
+	}
+
+	expr.WriteString("$")
+	p.regex = regexp.MustCompile(expr.String())
+}
+
+// relativeTo resolves a pattern without a directory against dir
+func (p *FilePattern) relativeTo(dir string) *FilePattern {
+	if p.dir != "." {
+		return p
+	}
+	clone := *p
+	clone.dir = dir
+	return &clone
+}
+
+// String returns the original pattern
+func (p *FilePattern) String() string {
+	return p.pattern
+}
+
+// Dir returns the directory backups are written to
+func (p *FilePattern) Dir() string {
+	return p.dir
+}
+
+// HasIndex reports whether the pattern contains %i
+func (p *FilePattern) HasIndex() bool {
+	for _, part := range p.parts {
+		if part.token == 'i' {
+			return true
+		}
+	}
+	return false
+}
+
+// Compressed reports whether backups should be gzip compressed
+func (p *FilePattern) Compressed() bool {
+	return strings.HasSuffix(p.pattern, ".gz")
+}
+
+// Format renders the backup path for the given time and index
+func (p *FilePattern) Format(t time.Time, index int) string {
+	var buf strings.Builder
+	for _, part := range p.parts {
+		switch part.token {
+		case 'i':
+			buf.WriteString(strconv.Itoa(index))
+		case 'd':
+			buf.WriteString(t.Format(part.layout))
+		default:
+			buf.WriteString(part.literal)
+		}
+	}
+	if p.dir == "." {
+		return buf.String()
+	}
+	return filepath.Join(p.dir, buf.String())
+}
+
+// Match reports whether a base file name was produced by this pattern
+// and returns its index (0 if the pattern has no %i)
+func (p *FilePattern) Match(name string) (int, bool) {
+	m := p.regex.FindStringSubmatch(name)
+	if m == nil {
+		return 0, false
+	}
+
+	index := 0
+	group := 1
+	for _, part := range p.parts {
+		switch part.token {
+		case 'i':
+			fmt.Sscanf(m[group], "%d", &index)
+			group++
+		case 'd':
+			if _, err := time.Parse(part.layout, m[group]); err != nil {
+				return 0, false
+			}
+			group++
+		}
+	}
+	return index, true
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"sync"
@@ -114,22 +115,29 @@ func (f *RegexFilter) Decide(entry *Entry) FilterResult {
 	return f.onMismatch
 }
 
-// MarkerFilter filters based on marker
+// MarkerFilter filters based on marker. An entry matches when its marker is
+// one of the filter's markers or a descendant of one ("SQL.SLOW" matches "SQL").
 type MarkerFilter struct {
-	marker     string
+	markers    []string
 	onMatch    FilterResult
 	onMismatch FilterResult
 }
 
-// NewMarkerFilter creates a filter for a specific marker
-func NewMarkerFilter(marker string) *MarkerFilter {
+// NewMarkerFilter creates a filter for one or more markers
+func NewMarkerFilter(markers ...string) *MarkerFilter {
 	return &MarkerFilter{
-		marker:     marker,
+		markers:    markers,
 		onMatch:    ACCEPT,
 		onMismatch: NEUTRAL,
 	}
 }
 
+// Add adds a marker
+func (f *MarkerFilter) Add(marker string) *MarkerFilter {
+	f.markers = append(f.markers, marker)
+	return f
+}
+
 // WithOnMatch sets the result when filter matches
 func (f *MarkerFilter) WithOnMatch(result FilterResult) *MarkerFilter {
 	f.onMatch = result
@@ -144,8 +152,10 @@ func (f *MarkerFilter) WithOnMismatch(result FilterResult) *MarkerFilter {
 
 // Decide implements Filter
 func (f *MarkerFilter) Decide(entry *Entry) FilterResult {
-	if strings.EqualFold(entry.Marker, f.marker) {
-		return f.onMatch
+	for _, m := range f.markers {
+		if MarkerIsInstanceOf(entry.Marker, m) {
+			return f.onMatch
+		}
 	}
 	return f.onMismatch
 }
@@ -206,6 +216,63 @@ func (f *CompositeFilter) Decide(entry *Entry) FilterResult {
 	return NEUTRAL
 }
 
+// NotFilter inverts another filter: ACCEPT becomes DENY and vice versa,
+// NEUTRAL stays NEUTRAL. NewNotFilter(NewMarkerFilter("SQL")) drops SQL
+// entries and leaves everything else to the remaining filters.
+type NotFilter struct {
+	inner Filter
+}
+
+// NewNotFilter creates a filter inverting inner
+func NewNotFilter(inner Filter) *NotFilter {
+	return &NotFilter{inner: inner}
+}
+
+// Decide implements Filter
+func (f *NotFilter) Decide(entry *Entry) FilterResult {
+	switch f.inner.Decide(entry) {
+	case ACCEPT:
+		return DENY
+	case DENY:
+		return ACCEPT
+	}
+	return NEUTRAL
+}
+
+// NoMarkerFilter matches entries logged without a marker
+type NoMarkerFilter struct {
+	onMatch    FilterResult
+	onMismatch FilterResult
+}
+
+// NewNoMarkerFilter creates a filter accepting unmarked entries and denying marked ones
+func NewNoMarkerFilter() *NoMarkerFilter {
+	return &NoMarkerFilter{
+		onMatch:    ACCEPT,
+		onMismatch: DENY,
+	}
+}
+
+// WithOnMatch sets the result for entries without a marker
+func (f *NoMarkerFilter) WithOnMatch(result FilterResult) *NoMarkerFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result for entries with a marker
+func (f *NoMarkerFilter) WithOnMismatch(result FilterResult) *NoMarkerFilter {
+	f.onMismatch = result
+	return f
+}
+
+// Decide implements Filter
+func (f *NoMarkerFilter) Decide(entry *Entry) FilterResult {
+	if entry.Marker == "" {
+		return f.onMatch
+	}
+	return f.onMismatch
+}
+
 // ThresholdFilter is an alias for LevelFilter (log4j2 compatibility)
 type ThresholdFilter = LevelFilter
 
@@ -236,9 +303,37 @@ type BurstFilter struct {
 	onMatch    FilterResult
 	onMismatch FilterResult
 
+	bucket tokenBucket
+	levels map[Level]*levelBurst
+	mu     sync.Mutex
+}
+
+// levelBurst is the rate limit of a single level, rate < 0 means unlimited
+type levelBurst struct {
+	rate     float64
+	maxBurst int
+	bucket   tokenBucket
+}
+
+// tokenBucket refills at rate tokens per second up to maxBurst
+type tokenBucket struct {
 	tokens     float64
 	lastRefill time.Time
-	mu         sync.Mutex
+}
+
+// take refills the bucket and consumes a token if one is available
+func (b *tokenBucket) take(now time.Time, rate float64, maxBurst int) bool {
+	b.tokens += now.Sub(b.lastRefill).Seconds() * rate
+	if b.tokens > float64(maxBurst) {
+		b.tokens = float64(maxBurst)
+	}
+	b.lastRefill = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	return false
 }
 
 // NewBurstFilter creates a new burst filter
@@ -249,9 +344,31 @@ func NewBurstFilter(level Level, rate float64, maxBurst int) *BurstFilter {
 		maxBurst:   maxBurst,
 		onMatch:    ACCEPT,
 		onMismatch: DENY,
-		tokens:     float64(maxBurst),
-		lastRefill: time.Now(),
+		bucket:     tokenBucket{tokens: float64(maxBurst), lastRefill: time.Now()},
+	}
+}
+
+// WithLevelRate gives level its own bucket instead of the shared one,
+// e.g. WithLevelRate(DEBUG, 1, 5).WithLevelRate(INFO, 10, 100).WithUnlimited(WARN, ERROR, FATAL).
+// Levels configured here are limited even below the filter's level.
+func (f *BurstFilter) WithLevelRate(level Level, rate float64, maxBurst int) *BurstFilter {
+	if f.levels == nil {
+		f.levels = make(map[Level]*levelBurst)
+	}
+	f.levels[level] = &levelBurst{
+		rate:     rate,
+		maxBurst: maxBurst,
+		bucket:   tokenBucket{tokens: float64(maxBurst), lastRefill: time.Now()},
+	}
+	return f
+}
+
+// WithUnlimited exempts levels from rate limiting
+func (f *BurstFilter) WithUnlimited(levels ...Level) *BurstFilter {
+	for _, level := range levels {
+		f.WithLevelRate(level, -1, 0)
 	}
+	return f
 }
 
 // WithOnMatch sets the result when filter matches (allowed)
@@ -268,6 +385,18 @@ func (f *BurstFilter) WithOnMismatch(result FilterResult) *BurstFilter {
 
 // Decide implements Filter
 func (f *BurstFilter) Decide(entry *Entry) FilterResult {
+	if lb, ok := f.levels[entry.Level]; ok {
+		if lb.rate < 0 {
+			return f.onMatch
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if lb.bucket.take(time.Now(), lb.rate, lb.maxBurst) {
+			return f.onMatch
+		}
+		return f.onMismatch
+	}
+
 	if entry.Level < f.level {
 		// If level is lower than threshold, this filter doesn't apply (passes neutral)
 		// Or logic: user said "BurstFilter level: INFO". Usually means limit INFO logs.
@@ -279,21 +408,29 @@ func (f *BurstFilter) Decide(entry *Entry) FilterResult {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	now := time.Now()
-	elapsed := now.Sub(f.lastRefill).Seconds()
-	f.tokens += elapsed * f.rate
-	if f.tokens > float64(f.maxBurst) {
-		f.tokens = float64(f.maxBurst)
-	}
-	f.lastRefill = now
-
-	if f.tokens >= 1 {
-		f.tokens--
+	if f.bucket.take(time.Now(), f.rate, f.maxBurst) {
 		return f.onMatch
 	}
 	return f.onMismatch
 }
 
+// FilterFactory creates a filter from its configuration map
+type FilterFactory func(config map[string]interface{}) (Filter, error)
+
+var (
+	filterFactoriesMu sync.RWMutex
+	filterFactories   = make(map[string]FilterFactory)
+)
+
+// RegisterFilterFactory makes a custom filter type available to ParseFilter
+// and config files. Type names are case-insensitive, a registered type takes
+// precedence over a built-in one of the same name.
+func RegisterFilterFactory(typeName string, factory FilterFactory) {
+	filterFactoriesMu.Lock()
+	defer filterFactoriesMu.Unlock()
+	filterFactories[strings.ToLower(typeName)] = factory
+}
+
 // ParseFilter creates a filter from configuration map
 func ParseFilter(config map[string]interface{}) Filter {
 	if config == nil {
@@ -305,6 +442,17 @@ func ParseFilter(config map[string]interface{}) Filter {
 		return nil
 	}
 
+	filterFactoriesMu.RLock()
+	factory := filterFactories[strings.ToLower(typ)]
+	filterFactoriesMu.RUnlock()
+	if factory != nil {
+		filter, err := factory(config)
+		if err != nil {
+			return nil
+		}
+		return filter
+	}
+
 	var onMatch = ACCEPT
 	var onMismatch = DENY
 
@@ -317,13 +465,97 @@ func ParseFilter(config map[string]interface{}) Filter {
 
 	switch strings.ToLower(typ) {
 	case "marker":
+		// {"type": "marker", "marker": "SQL"} or {"type": "marker", "markers": ["SQL", "API"]}
+		markers := configStrings(config["markers"])
 		if marker, ok := config["marker"].(string); ok {
-			return NewMarkerFilter(marker).WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+			markers = append(markers, marker)
+		}
+		if len(markers) > 0 {
+			return NewMarkerFilter(markers...).WithOnMatch(onMatch).WithOnMismatch(onMismatch)
 		}
 	case "level", "threshold":
 		if levelStr, ok := config["level"].(string); ok {
 			return NewThresholdFilter(ParseLevel(levelStr)).WithOnMatch(onMatch).WithOnMismatch(onMismatch)
 		}
+	case "composite", "all", "any":
+		// {"type": "composite", "mode": "ANY", "filters": [{"type": "marker", ...}, {"type": "regex", ...}]}
+		mode := ALL
+		if m, _ := config["mode"].(string); strings.EqualFold(m, "any") || strings.EqualFold(typ, "any") {
+			mode = ANY
+		}
+		f := NewCompositeFilter(mode)
+		children, _ := config["filters"].([]interface{})
+		for _, child := range children {
+			childCfg, _ := child.(map[string]interface{})
+			if filter := ParseFilter(childCfg); filter != nil {
+				f.Add(filter)
+			}
+		}
+		if len(f.filters) == 0 {
+			return nil
+		}
+		return f
+	case "not":
+		// {"type": "not", "filter": {"type": "marker", "marker": "SQL"}}
+		inner, _ := config["filter"].(map[string]interface{})
+		if f := ParseFilter(inner); f != nil {
+			return NewNotFilter(f)
+		}
+	case "no_marker":
+		return NewNoMarkerFilter().WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+	case "regex":
+		// {"type": "regex", "pattern": "(?i)password", "on_match": "DENY", "on_mismatch": "NEUTRAL"}
+		pattern, _ := config["pattern"].(string)
+		f, err := NewRegexFilter(pattern)
+		if err != nil {
+			return nil
+		}
+		return f.WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+	case "field_regex":
+		// {"type": "field_regex", "keys": ["path"], "pattern": "^/healthz"}, denies matches unless configured
+		pattern, _ := config["pattern"].(string)
+		keys := configStrings(config["keys"])
+		if key, ok := config["key"].(string); ok {
+			keys = append(keys, key)
+		}
+		f, err := NewFieldRegexFilter(pattern, keys...)
+		if err != nil {
+			return nil
+		}
+		if _, ok := config["on_match"]; ok {
+			f.WithOnMatch(onMatch)
+		}
+		if _, ok := config["on_mismatch"]; ok {
+			f.WithOnMismatch(onMismatch)
+		}
+		return f
+	case "time":
+		// {"type": "time", "start": "09:00", "end": "18:00", "timezone": "Asia/Shanghai"}
+		start, _ := config["start"].(string)
+		end, _ := config["end"].(string)
+		f, err := NewTimeFilter(start, end)
+		if err != nil {
+			return nil
+		}
+		if tz, ok := config["timezone"].(string); ok {
+			if loc, err := time.LoadLocation(tz); err == nil {
+				f.WithLocation(loc)
+			}
+		}
+		return f.WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+	case "dynamic_threshold":
+		// {"type": "dynamic_threshold", "level": "INFO", "key": "user_id", "levels": {"alice": "DEBUG"}}
+		levelStr, _ := config["level"].(string)
+		f := NewDynamicThresholdFilter(ParseLevel(levelStr))
+		if key, ok := config["key"].(string); ok {
+			levels, _ := config["levels"].(map[string]interface{})
+			for value, l := range levels {
+				if s, ok := l.(string); ok {
+					f.WithContextLevel(key, value, ParseLevel(s))
+				}
+			}
+		}
+		return f.WithOnMatch(onMatch).WithOnMismatch(onMismatch)
 	case "burst":
 		levelStr, _ := config["level"].(string)
 		level := ParseLevel(levelStr)
@@ -340,7 +572,130 @@ func ParseFilter(config map[string]interface{}) Filter {
 				maxBurst = int(m)
 			}
 		}
-		return NewBurstFilter(level, rate, maxBurst).WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+		f := NewBurstFilter(level, rate, maxBurst)
+		// "levels": {"DEBUG": 1, "INFO": {"rate": 10, "max_burst": 100}, "WARN": "unlimited"}
+		levels, _ := config["levels"].(map[string]interface{})
+		for name, v := range levels {
+			l := ParseLevel(strings.ToUpper(name))
+			switch lv := v.(type) {
+			case string:
+				if strings.EqualFold(lv, "unlimited") {
+					f.WithUnlimited(l)
+				}
+			case map[string]interface{}:
+				r := configNumber(lv["rate"])
+				burst := int(configNumber(lv["max_burst"]))
+				if burst == 0 {
+					burst = int(r * 10)
+				}
+				f.WithLevelRate(l, r, burst)
+			default:
+				if r := configNumber(v); r < 0 {
+					f.WithUnlimited(l)
+				} else {
+					f.WithLevelRate(l, r, int(r*10))
+				}
+			}
+		}
+		return f.WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+	case "keyed_burst":
+		// {"type": "keyed_burst", "key": "client_ip", "level": "INFO", "rate": 10, "max_burst": 100}
+		key, _ := config["key"].(string)
+		levelStr, _ := config["level"].(string)
+		return NewKeyedBurstFilter(key, ParseLevel(levelStr), configNumber(config["rate"]), int(configNumber(config["max_burst"]))).
+			WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+	case "logger_name", "logger":
+		// {"type": "logger_name", "names": ["pkg.http.client", "pkg.*.cache"], "on_match": "DENY"}
+		f := NewLoggerNameFilter()
+		if name, ok := config["name"].(string); ok {
+			f.Add(name)
+		}
+		for _, v := range configStrings(config["names"]) {
+			f.Add(v)
+		}
+		return f.WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+	case "expression", "script":
+		// {"type": "expression", "expression": "level >= WARN && fields.path !~ '^/healthz'"}
+		expr, _ := config["expression"].(string)
+		f, err := NewExpressionFilter(expr)
+		if err != nil {
+			return nil
+		}
+		return f.WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+	case "sampling":
+		// {"type": "sampling", "every": 10} or {"type": "sampling", "probability": 0.1},
+		// optional "level" (highest sampled level) and "key" ("template" or an MDC key)
+		var f *SamplingFilter
+		if p := configNumber(config["probability"]); p > 0 {
+			f = NewRandomSamplingFilter(p)
+		} else {
+			f = NewSamplingFilter(int(configNumber(config["every"])))
+		}
+		if levelStr, ok := config["level"].(string); ok {
+			f.WithMaxLevel(ParseLevel(levelStr))
+		}
+		if key, _ := config["key"].(string); key == "template" {
+			f.ByTemplate()
+		} else if key != "" {
+			f.ByContext(key)
+		}
+		return f.WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+	case "dedup":
+		// {"type": "dedup", "window": "1m"}, summaries go to the owning appender
+		window := time.Minute
+		if w, ok := config["window"].(string); ok && parseDuration(w) > 0 {
+			window = parseDuration(w)
+		}
+		return NewDedupFilter(window)
+	case "context_map", "mdc":
+		// {"type": "context_map", "operator": "or", "pairs": {"tenant": "acme"}}
+		mode := ALL
+		if op, _ := config["operator"].(string); strings.EqualFold(op, "or") || strings.EqualFold(op, "any") {
+			mode = ANY
+		}
+		f := NewContextMapFilter(mode).WithOnMatch(onMatch).WithOnMismatch(onMismatch)
+		pairs, _ := config["pairs"].(map[string]interface{})
+		for k, v := range pairs {
+			f.Equals(k, fmt.Sprint(v))
+		}
+		return f
+	}
+	return nil
+}
+
+// configNumber reads a number from a config value, which is float64 when
+// decoded from JSON/YAML but may be an int in maps built by hand
+func configNumber(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	}
+	return 0
+}
+
+// configStrings reads a list of strings from a config value
+func configStrings(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		return []string{list}
 	}
 	return nil
 }
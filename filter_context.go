@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ContextMapFilter matches MDC context values, e.g. DEBUG logging only for
+// one tenant. Conditions are combined with ALL (every condition must hold)
+// or ANY (one is enough), a missing key never matches.
+type ContextMapFilter struct {
+	conditions []contextCondition
+	mode       CompositeMode
+	onMatch    FilterResult
+	onMismatch FilterResult
+}
+
+type contextCondition struct {
+	key   string
+	match func(value string) bool
+}
+
+// NewContextMapFilter creates an empty context filter combining conditions with mode
+func NewContextMapFilter(mode CompositeMode) *ContextMapFilter {
+	return &ContextMapFilter{
+		mode:       mode,
+		onMatch:    ACCEPT,
+		onMismatch: NEUTRAL,
+	}
+}
+
+// Equals adds a condition that key has exactly value
+func (f *ContextMapFilter) Equals(key, value string) *ContextMapFilter {
+	f.conditions = append(f.conditions, contextCondition{key, func(v string) bool { return v == value }})
+	return f
+}
+
+// Prefix adds a condition that key starts with prefix
+func (f *ContextMapFilter) Prefix(key, prefix string) *ContextMapFilter {
+	f.conditions = append(f.conditions, contextCondition{key, func(v string) bool { return strings.HasPrefix(v, prefix) }})
+	return f
+}
+
+// Matches adds a condition that key matches re
+func (f *ContextMapFilter) Matches(key string, re *regexp.Regexp) *ContextMapFilter {
+	f.conditions = append(f.conditions, contextCondition{key, re.MatchString})
+	return f
+}
+
+// WithOnMatch sets the result when the conditions hold
+func (f *ContextMapFilter) WithOnMatch(result FilterResult) *ContextMapFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result when they don't
+func (f *ContextMapFilter) WithOnMismatch(result FilterResult) *ContextMapFilter {
+	f.onMismatch = result
+	return f
+}
+
+// Decide implements Filter
+func (f *ContextMapFilter) Decide(entry *Entry) FilterResult {
+	if len(f.conditions) == 0 {
+		return NEUTRAL
+	}
+	for _, c := range f.conditions {
+		matched := false
+		if v, ok := entry.Context[c.key]; ok {
+			matched = c.match(fmt.Sprint(v))
+		}
+		if f.mode == ANY && matched {
+			return f.onMatch
+		}
+		if f.mode == ALL && !matched {
+			return f.onMismatch
+		}
+	}
+	if f.mode == ALL {
+		return f.onMatch
+	}
+	return f.onMismatch
+}
@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DedupFilter suppresses consecutive identical messages (same level, logger
+// and message) within a window. When a different message arrives or the
+// window expires, a "last message repeated N times" entry is written to the
+// output appender, usually the appender the filter is attached to:
+//
+//	console := NewConsoleAppender()
+//	dedup := NewDedupFilter(time.Minute).WithOutput(console)
+//	console.WithFilter(dedup)
+type DedupFilter struct {
+	window     time.Duration
+	output     Appender
+	onMatch    FilterResult
+	onMismatch FilterResult
+
+	mu        sync.Mutex
+	last      *Entry
+	first     time.Time // start of the current window
+	count     int       // suppressed repeats in the current window
+	timer     *time.Timer
+	summaries map[*Entry]bool
+}
+
+// NewDedupFilter creates a filter suppressing repeats within window
+func NewDedupFilter(window time.Duration) *DedupFilter {
+	return &DedupFilter{
+		window:     window,
+		onMatch:    NEUTRAL,
+		onMismatch: DENY,
+		summaries:  make(map[*Entry]bool),
+	}
+}
+
+// WithOutput sets the appender repeat summaries are written to
+func (f *DedupFilter) WithOutput(output Appender) *DedupFilter {
+	f.mu.Lock()
+	f.output = output
+	f.mu.Unlock()
+	return f
+}
+
+// WithOnMatch sets the result for entries that are let through
+func (f *DedupFilter) WithOnMatch(result FilterResult) *DedupFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result for suppressed repeats
+func (f *DedupFilter) WithOnMismatch(result FilterResult) *DedupFilter {
+	f.onMismatch = result
+	return f
+}
+
+// Decide implements Filter
+func (f *DedupFilter) Decide(entry *Entry) FilterResult {
+	f.mu.Lock()
+	if f.summaries[entry] {
+		delete(f.summaries, entry)
+		f.mu.Unlock()
+		return NEUTRAL
+	}
+
+	now := entry.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if f.last != nil && now.Sub(f.first) < f.window &&
+		entry.Message == f.last.Message && entry.Level == f.last.Level && entry.Logger == f.last.Logger {
+		f.count++
+		if f.timer == nil {
+			f.timer = time.AfterFunc(f.window-now.Sub(f.first), f.expire)
+		}
+		f.mu.Unlock()
+		return f.onMismatch
+	}
+
+	summary, output := f.takeSummary()
+	f.last = &Entry{Level: entry.Level, Logger: entry.Logger, Message: entry.Message}
+	f.first = now
+	f.mu.Unlock()
+
+	// Written before the new entry, which is appended once we return
+	if summary != nil && output != nil {
+		_ = output.Append(summary)
+	}
+	return f.onMatch
+}
+
+// Flush writes the pending repeat summary, e.g. before shutdown
+func (f *DedupFilter) Flush() {
+	f.mu.Lock()
+	summary, output := f.takeSummary()
+	f.last = nil
+	f.mu.Unlock()
+
+	if summary != nil && output != nil {
+		_ = output.Append(summary)
+	}
+}
+
+// expire runs when the window of a suppressed message ends
+func (f *DedupFilter) expire() {
+	f.mu.Lock()
+	f.timer = nil
+	summary, output := f.takeSummary()
+	// Later repeats start a new window
+	f.first = time.Now()
+	f.mu.Unlock()
+
+	if summary != nil && output != nil {
+		_ = output.Append(summary)
+	}
+}
+
+// takeSummary builds the summary for suppressed repeats and resets the count,
+// must be called with f.mu held
+func (f *DedupFilter) takeSummary() (*Entry, Appender) {
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	if f.count == 0 || f.last == nil {
+		return nil, nil
+	}
+	summary := &Entry{
+		Time:    time.Now(),
+		Level:   f.last.Level,
+		Logger:  f.last.Logger,
+		Message: fmt.Sprintf("last message repeated %d times", f.count),
+		Fields:  map[string]interface{}{"repeated": f.count},
+	}
+	f.count = 0
+	if f.output != nil {
+		f.summaries[summary] = true
+	}
+	return summary, f.output
+}
+
+// bindFilterOutput points dedup filters without an output at the appender
+// they are attached to, looking inside composite filters
+func bindFilterOutput(filter Filter, appender Appender) {
+	switch f := filter.(type) {
+	case *DedupFilter:
+		f.mu.Lock()
+		if f.output == nil {
+			f.output = appender
+		}
+		f.mu.Unlock()
+	case *CompositeFilter:
+		for _, inner := range f.filters {
+			bindFilterOutput(inner, appender)
+		}
+	case *NotFilter:
+		bindFilterOutput(f.inner, appender)
+	}
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// DynamicThresholdFilter is a threshold filter whose level can be changed
+// while the application runs, e.g. to turn on DEBUG for one appender while
+// investigating an incident. Like log4j2's DynamicThresholdFilter, a context
+// key can also lower the threshold for selected requests or users.
+type DynamicThresholdFilter struct {
+	level      atomic.Int32
+	key        string
+	overrides  map[string]Level
+	onMatch    FilterResult
+	onMismatch FilterResult
+}
+
+// NewDynamicThresholdFilter creates a filter accepting entries at or above level
+func NewDynamicThresholdFilter(level Level) *DynamicThresholdFilter {
+	f := &DynamicThresholdFilter{
+		onMatch:    ACCEPT,
+		onMismatch: DENY,
+	}
+	f.level.Store(int32(level))
+	return f
+}
+
+// SetLevel changes the threshold, safe to call concurrently with logging
+func (f *DynamicThresholdFilter) SetLevel(level Level) {
+	f.level.Store(int32(level))
+}
+
+// Level returns the current threshold
+func (f *DynamicThresholdFilter) Level() Level {
+	return Level(f.level.Load())
+}
+
+// WithContextLevel uses level instead of the threshold for entries whose
+// context key has the given value, e.g. ("user_id", "alice", DEBUG).
+// Overrides are configuration, set them before the filter is in use.
+func (f *DynamicThresholdFilter) WithContextLevel(key, value string, level Level) *DynamicThresholdFilter {
+	if f.overrides == nil || f.key != key {
+		f.key = key
+		f.overrides = make(map[string]Level)
+	}
+	f.overrides[value] = level
+	return f
+}
+
+// WithOnMatch sets the result when filter matches
+func (f *DynamicThresholdFilter) WithOnMatch(result FilterResult) *DynamicThresholdFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result when filter doesn't match
+func (f *DynamicThresholdFilter) WithOnMismatch(result FilterResult) *DynamicThresholdFilter {
+	f.onMismatch = result
+	return f
+}
+
+// Decide implements Filter
+func (f *DynamicThresholdFilter) Decide(entry *Entry) FilterResult {
+	threshold := f.Level()
+	if f.key != "" {
+		if v, ok := entry.Context[f.key]; ok {
+			if level, ok := f.overrides[fmt.Sprint(v)]; ok {
+				threshold = level
+			}
+		}
+	}
+	if entry.Level >= threshold {
+		return f.onMatch
+	}
+	return f.onMismatch
+}
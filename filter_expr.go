@@ -0,0 +1,445 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExpressionFilter evaluates a boolean expression over the entry, for
+// routing rules that would otherwise need custom Go code:
+//
+//	level >= WARN && marker != "AUDIT"
+//	fields.path =~ "^/api/" and not (fields.status < 400)
+//	logger startsWith "pkg.http" || context.tenant == "acme"
+//
+// Variables: level, logger, message, marker, code, error, fields.<key> and
+// context.<key> (missing keys are empty). Operators: == != < <= > >=,
+// =~ and !~ (regex), contains, startsWith, endsWith, && (and), || (or),
+// ! (not) and parentheses. Level names compare by severity, a bare value
+// is true when it is non-empty, non-zero or true.
+type ExpressionFilter struct {
+	source     string
+	eval       exprNode
+	onMatch    FilterResult
+	onMismatch FilterResult
+}
+
+// exprNode evaluates part of an expression
+type exprNode func(entry *Entry) interface{}
+
+// NewExpressionFilter compiles an expression filter
+func NewExpressionFilter(expression string) (*ExpressionFilter, error) {
+	p := &exprParser{}
+	if err := p.tokenize(expression); err != nil {
+		return nil, err
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("expression: unexpected %q", p.tokens[p.pos].text)
+	}
+	return &ExpressionFilter{
+		source:     expression,
+		eval:       node,
+		onMatch:    ACCEPT,
+		onMismatch: NEUTRAL,
+	}, nil
+}
+
+// MustExpressionFilter compiles an expression filter, panics on invalid expressions
+func MustExpressionFilter(expression string) *ExpressionFilter {
+	f, err := NewExpressionFilter(expression)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// WithOnMatch sets the result when the expression is true
+func (f *ExpressionFilter) WithOnMatch(result FilterResult) *ExpressionFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result when the expression is false
+func (f *ExpressionFilter) WithOnMismatch(result FilterResult) *ExpressionFilter {
+	f.onMismatch = result
+	return f
+}
+
+// String returns the expression source
+func (f *ExpressionFilter) String() string {
+	return f.source
+}
+
+// Decide implements Filter
+func (f *ExpressionFilter) Decide(entry *Entry) FilterResult {
+	if exprTruthy(f.eval(entry)) {
+		return f.onMatch
+	}
+	return f.onMismatch
+}
+
+type exprTokenKind int
+
+const (
+	tokIdent exprTokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) tokenize(s string) error {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			var sb strings.Builder
+			for ; j < len(s) && s[j] != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				sb.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return fmt.Errorf("expression: unterminated string at %d", i)
+			}
+			p.tokens = append(p.tokens, exprToken{tokString, sb.String()})
+			i = j + 1
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			j := i + 1
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+				j++
+			}
+			p.tokens = append(p.tokens, exprToken{tokNumber, s[i:j]})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || s[j] == '.' || s[j] == '-' || unicode.IsLetter(rune(s[j])) || s[j] >= '0' && s[j] <= '9') {
+				j++
+			}
+			p.tokens = append(p.tokens, exprToken{tokIdent, s[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "(", ")", "!", "<", ">"} {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("expression: unexpected %q at %d", c, i)
+			}
+			p.tokens = append(p.tokens, exprToken{tokOp, op})
+			i += len(op)
+		}
+	}
+	return nil
+}
+
+func (p *exprParser) peek() (exprToken, bool) {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos], true
+	}
+	return exprToken{}, false
+}
+
+// accept consumes the next token if it is one of ops (operators or keywords)
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	t, ok := p.peek()
+	if !ok || t.kind == tokString || t.kind == tokNumber {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op || t.kind == tokIdent && strings.EqualFold(t.text, op) {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||", "or"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e *Entry) interface{} { return exprTruthy(l(e)) || exprTruthy(right(e)) }
+	}
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&", "and"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e *Entry) interface{} { return exprTruthy(l(e)) && exprTruthy(right(e)) }
+	}
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if _, ok := p.accept("!", "not"); ok {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(e *Entry) interface{} { return !exprTruthy(inner(e)) }, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">", "=~", "!~", "contains", "startsWith", "endsWith")
+	if !ok {
+		return left, nil
+	}
+
+	if op == "=~" || op == "!~" {
+		t, ok := p.peek()
+		if !ok || t.kind != tokString {
+			return nil, fmt.Errorf("expression: %s needs a string pattern", op)
+		}
+		p.pos++
+		re, err := regexp.Compile(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("expression: %v", err)
+		}
+		negate := op == "!~"
+		return func(e *Entry) interface{} {
+			return re.MatchString(exprString(left(e))) != negate
+		}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return func(e *Entry) interface{} { return exprCompare(op, left(e), right(e)) }, nil
+}
+
+func (p *exprParser) parseOperand() (exprNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("expression: unexpected end")
+	}
+	p.pos++
+
+	switch t.kind {
+	case tokString:
+		value := t.text
+		return func(*Entry) interface{} { return value }, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expression: invalid number %q", t.text)
+		}
+		return func(*Entry) interface{} { return n }, nil
+	case tokOp:
+		if t.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, fmt.Errorf("expression: missing )")
+			}
+			return inner, nil
+		}
+		return nil, fmt.Errorf("expression: unexpected %q", t.text)
+	}
+	return exprVariable(t.text)
+}
+
+// exprVariable resolves an identifier to an entry accessor or constant
+func exprVariable(name string) (exprNode, error) {
+	switch strings.ToLower(name) {
+	case "level":
+		return func(e *Entry) interface{} { return e.Level }, nil
+	case "logger":
+		return func(e *Entry) interface{} { return e.Logger }, nil
+	case "message", "msg":
+		return func(e *Entry) interface{} { return e.Message }, nil
+	case "marker":
+		return func(e *Entry) interface{} { return e.Marker }, nil
+	case "code":
+		return func(e *Entry) interface{} { return e.Code }, nil
+	case "error", "err":
+		return func(e *Entry) interface{} {
+			if e.Error == nil {
+				return ""
+			}
+			return e.Error.Error()
+		}, nil
+	case "true":
+		return func(*Entry) interface{} { return true }, nil
+	case "false":
+		return func(*Entry) interface{} { return false }, nil
+	}
+	if key, ok := strings.CutPrefix(name, "fields."); ok {
+		return func(e *Entry) interface{} {
+			v, _ := e.FieldValue(key)
+			return v
+		}, nil
+	}
+	if key, ok := strings.CutPrefix(name, "context."); ok {
+		return func(e *Entry) interface{} { return e.Context[key] }, nil
+	}
+	if level, ok := levelValues[strings.ToUpper(name)]; ok {
+		return func(*Entry) interface{} { return level }, nil
+	}
+	return nil, fmt.Errorf("expression: unknown variable %q", name)
+}
+
+// exprCompare applies a comparison operator, comparing levels by severity,
+// numbers numerically and everything else as strings
+func exprCompare(op string, a, b interface{}) bool {
+	switch op {
+	case "contains":
+		return strings.Contains(exprString(a), exprString(b))
+	case "startsWith":
+		return strings.HasPrefix(exprString(a), exprString(b))
+	case "endsWith":
+		return strings.HasSuffix(exprString(a), exprString(b))
+	}
+
+	var cmp int
+	la, aIsLevel := a.(Level)
+	lb, bIsLevel := b.(Level)
+	if aIsLevel || bIsLevel {
+		if !aIsLevel {
+			la = ParseLevel(strings.ToUpper(exprString(a)))
+		}
+		if !bIsLevel {
+			lb = ParseLevel(strings.ToUpper(exprString(b)))
+		}
+		cmp = int(la) - int(lb)
+	} else if na, ok := exprNumber(a); ok {
+		if nb, ok := exprNumber(b); ok {
+			switch {
+			case na < nb:
+				cmp = -1
+			case na > nb:
+				cmp = 1
+			}
+		} else {
+			cmp = strings.Compare(exprString(a), exprString(b))
+		}
+	} else {
+		cmp = strings.Compare(exprString(a), exprString(b))
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+func exprNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func exprString(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func exprTruthy(v interface{}) bool {
+	switch b := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return b
+	case string:
+		return b != ""
+	}
+	if n, ok := exprNumber(v); ok {
+		return n != 0
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// FieldRegexFilter matches a regex against selected field or context values
+// rather than the message, e.g. dropping health check access logs:
+//
+//	MustFieldRegexFilter(`^/(healthz|readyz)$`, "path")
+//
+// Fields take precedence over context for the same key, the key "message"
+// refers to the message itself. By default a match is denied and anything
+// else is left to the other filters.
+type FieldRegexFilter struct {
+	keys       []string
+	pattern    *regexp.Regexp
+	onMatch    FilterResult
+	onMismatch FilterResult
+}
+
+// NewFieldRegexFilter creates a filter matching pattern against the values of keys
+func NewFieldRegexFilter(pattern string, keys ...string) (*FieldRegexFilter, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &FieldRegexFilter{
+		keys:       keys,
+		pattern:    re,
+		onMatch:    DENY,
+		onMismatch: NEUTRAL,
+	}, nil
+}
+
+// MustFieldRegexFilter creates a filter, panics on invalid pattern
+func MustFieldRegexFilter(pattern string, keys ...string) *FieldRegexFilter {
+	f, err := NewFieldRegexFilter(pattern, keys...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// WithOnMatch sets the result when any value matches
+func (f *FieldRegexFilter) WithOnMatch(result FilterResult) *FieldRegexFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result when no value matches
+func (f *FieldRegexFilter) WithOnMismatch(result FilterResult) *FieldRegexFilter {
+	f.onMismatch = result
+	return f
+}
+
+// Decide implements Filter
+func (f *FieldRegexFilter) Decide(entry *Entry) FilterResult {
+	for _, key := range f.keys {
+		v, ok := entry.FieldValue(key)
+		if !ok {
+			v, ok = entry.Context[key]
+		}
+		if !ok && key == "message" {
+			v, ok = entry.Message, true
+		}
+		if ok && f.pattern.MatchString(fmt.Sprint(v)) {
+			return f.onMatch
+		}
+	}
+	return f.onMismatch
+}
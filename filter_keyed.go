@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultMaxBurstKeys bounds the buckets a KeyedBurstFilter keeps
+const defaultMaxBurstKeys = 10000
+
+// KeyedBurstFilter rate limits like BurstFilter but keeps a token bucket per
+// value of a field or MDC key (e.g. client IP), so one noisy client can't
+// drown everyone else's logs. Entries without the key share one bucket.
+type KeyedBurstFilter struct {
+	key        string
+	level      Level
+	rate       float64
+	maxBurst   int
+	maxKeys    int
+	onMatch    FilterResult
+	onMismatch FilterResult
+
+	buckets map[string]*tokenBucket
+	mu      sync.Mutex
+}
+
+// NewKeyedBurstFilter creates a per-key burst filter, key is looked up in
+// Fields first and then in the MDC context
+func NewKeyedBurstFilter(key string, level Level, rate float64, maxBurst int) *KeyedBurstFilter {
+	return &KeyedBurstFilter{
+		key:        key,
+		level:      level,
+		rate:       rate,
+		maxBurst:   maxBurst,
+		maxKeys:    defaultMaxBurstKeys,
+		onMatch:    ACCEPT,
+		onMismatch: DENY,
+		buckets:    make(map[string]*tokenBucket),
+	}
+}
+
+// WithMaxKeys bounds the number of tracked keys, idle buckets are evicted first
+func (f *KeyedBurstFilter) WithMaxKeys(n int) *KeyedBurstFilter {
+	f.maxKeys = n
+	return f
+}
+
+// WithOnMatch sets the result when filter matches (allowed)
+func (f *KeyedBurstFilter) WithOnMatch(result FilterResult) *KeyedBurstFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result when filter doesn't match (rate exhausted)
+func (f *KeyedBurstFilter) WithOnMismatch(result FilterResult) *KeyedBurstFilter {
+	f.onMismatch = result
+	return f
+}
+
+// Decide implements Filter
+func (f *KeyedBurstFilter) Decide(entry *Entry) FilterResult {
+	if entry.Level < f.level {
+		return NEUTRAL
+	}
+
+	key := ""
+	if v, ok := entry.FieldValue(f.key); ok {
+		key = fmt.Sprint(v)
+	} else if v, ok := entry.Context[f.key]; ok {
+		key = fmt.Sprint(v)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	bucket, ok := f.buckets[key]
+	if !ok {
+		if f.maxKeys > 0 && len(f.buckets) >= f.maxKeys {
+			f.evict(now)
+		}
+		bucket = &tokenBucket{tokens: float64(f.maxBurst), lastRefill: now}
+		f.buckets[key] = bucket
+	}
+
+	if bucket.take(now, f.rate, f.maxBurst) {
+		return f.onMatch
+	}
+	return f.onMismatch
+}
+
+// evict drops buckets that have refilled completely, or all of them if
+// every key is still active
+func (f *KeyedBurstFilter) evict(now time.Time) {
+	for k, b := range f.buckets {
+		if b.tokens+now.Sub(b.lastRefill).Seconds()*f.rate >= float64(f.maxBurst) {
+			delete(f.buckets, k)
+		}
+	}
+	if len(f.buckets) >= f.maxKeys {
+		f.buckets = make(map[string]*tokenBucket)
+	}
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"path"
+	"strings"
+)
+
+// LoggerNameFilter matches entries by logger name. A plain pattern matches
+// that logger and its descendants ("pkg.http" matches "pkg.http.client"),
+// a pattern containing *, ? or [ is matched as a glob ("pkg.*.client").
+type LoggerNameFilter struct {
+	patterns   []string
+	onMatch    FilterResult
+	onMismatch FilterResult
+}
+
+// NewLoggerNameFilter creates a filter matching any of the patterns
+// e.g. NewLoggerNameFilter("pkg.http.client").WithOnMatch(DENY) silences a subsystem
+func NewLoggerNameFilter(patterns ...string) *LoggerNameFilter {
+	return &LoggerNameFilter{
+		patterns:   patterns,
+		onMatch:    ACCEPT,
+		onMismatch: NEUTRAL,
+	}
+}
+
+// Add adds a pattern
+func (f *LoggerNameFilter) Add(pattern string) *LoggerNameFilter {
+	f.patterns = append(f.patterns, pattern)
+	return f
+}
+
+// WithOnMatch sets the result when filter matches
+func (f *LoggerNameFilter) WithOnMatch(result FilterResult) *LoggerNameFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result when filter doesn't match
+func (f *LoggerNameFilter) WithOnMismatch(result FilterResult) *LoggerNameFilter {
+	f.onMismatch = result
+	return f
+}
+
+// Decide implements Filter
+func (f *LoggerNameFilter) Decide(entry *Entry) FilterResult {
+	for _, p := range f.patterns {
+		if matchLoggerName(p, entry.Logger) {
+			return f.onMatch
+		}
+	}
+	return f.onMismatch
+}
+
+// matchLoggerName reports whether name is pattern, a descendant of it, or matches it as a glob
+func matchLoggerName(pattern, name string) bool {
+	if strings.ContainsAny(pattern, "*?[") {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}
+	return name == pattern || strings.HasPrefix(name, pattern+".")
+}
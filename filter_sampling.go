@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// maxSamplingKeys bounds the per-key counters, they are reset when exceeded
+const maxSamplingKeys = 10000
+
+// SamplingFilter down-samples high volume entries at or below a level
+// (INFO by default), either keeping 1 in every N entries or each entry with
+// a fixed probability. Counting can be done per message template or per
+// MDC value so rare messages aren't drowned by frequent ones.
+type SamplingFilter struct {
+	every       uint64
+	probability float64
+	maxLevel    Level
+	key         func(entry *Entry) string
+	onMatch     FilterResult
+	onMismatch  FilterResult
+
+	counters map[string]uint64
+	mu       sync.Mutex
+}
+
+// NewSamplingFilter keeps the first and then every n-th entry
+func NewSamplingFilter(n int) *SamplingFilter {
+	if n < 1 {
+		n = 1
+	}
+	return &SamplingFilter{
+		every:      uint64(n),
+		maxLevel:   INFO,
+		onMatch:    ACCEPT,
+		onMismatch: DENY,
+		counters:   make(map[string]uint64),
+	}
+}
+
+// NewRandomSamplingFilter keeps each entry with the given probability (0-1)
+func NewRandomSamplingFilter(probability float64) *SamplingFilter {
+	f := NewSamplingFilter(1)
+	f.every = 0
+	f.probability = probability
+	return f
+}
+
+// WithMaxLevel sets the highest level that is sampled, entries above pass neutrally
+func (f *SamplingFilter) WithMaxLevel(level Level) *SamplingFilter {
+	f.maxLevel = level
+	return f
+}
+
+// ByTemplate counts 1 in N separately for each message format string
+func (f *SamplingFilter) ByTemplate() *SamplingFilter {
+	f.key = func(entry *Entry) string { return entry.Template }
+	return f
+}
+
+// ByContext counts 1 in N separately for each value of an MDC key
+func (f *SamplingFilter) ByContext(key string) *SamplingFilter {
+	f.key = func(entry *Entry) string {
+		if v, ok := entry.Context[key]; ok {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	return f
+}
+
+// WithOnMatch sets the result for sampled entries
+func (f *SamplingFilter) WithOnMatch(result FilterResult) *SamplingFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result for dropped entries
+func (f *SamplingFilter) WithOnMismatch(result FilterResult) *SamplingFilter {
+	f.onMismatch = result
+	return f
+}
+
+// Decide implements Filter
+func (f *SamplingFilter) Decide(entry *Entry) FilterResult {
+	if entry.Level > f.maxLevel {
+		return NEUTRAL
+	}
+
+	if f.every == 0 {
+		if rand.Float64() < f.probability {
+			return f.onMatch
+		}
+		return f.onMismatch
+	}
+
+	key := ""
+	if f.key != nil {
+		key = f.key(entry)
+	}
+
+	f.mu.Lock()
+	if len(f.counters) >= maxSamplingKeys {
+		if _, ok := f.counters[key]; !ok {
+			f.counters = make(map[string]uint64)
+		}
+	}
+	n := f.counters[key]
+	f.counters[key] = n + 1
+	f.mu.Unlock()
+
+	if n%f.every == 0 {
+		return f.onMatch
+	}
+	return f.onMismatch
+}
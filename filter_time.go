@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeFilter matches entries logged within daily time windows, e.g. verbose
+// logging only during business hours. A window whose end is before its
+// start wraps past midnight ("22:00" to "06:00").
+type TimeFilter struct {
+	windows    []timeWindow
+	location   *time.Location
+	onMatch    FilterResult
+	onMismatch FilterResult
+}
+
+type timeWindow struct {
+	start, end time.Duration // offsets from midnight
+}
+
+// NewTimeFilter creates a filter for a daily window, times are "15:04" or "15:04:05"
+func NewTimeFilter(start, end string) (*TimeFilter, error) {
+	f := &TimeFilter{
+		location:   time.Local,
+		onMatch:    ACCEPT,
+		onMismatch: DENY,
+	}
+	if err := f.AddWindow(start, end); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// MustTimeFilter creates a time filter, panics on invalid times
+func MustTimeFilter(start, end string) *TimeFilter {
+	f, err := NewTimeFilter(start, end)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// AddWindow adds another daily window
+func (f *TimeFilter) AddWindow(start, end string) error {
+	s, err := parseClock(start)
+	if err != nil {
+		return err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return err
+	}
+	f.windows = append(f.windows, timeWindow{start: s, end: e})
+	return nil
+}
+
+// WithLocation sets the time zone windows are evaluated in (default local)
+func (f *TimeFilter) WithLocation(loc *time.Location) *TimeFilter {
+	f.location = loc
+	return f
+}
+
+// WithOnMatch sets the result when the entry is inside a window
+func (f *TimeFilter) WithOnMatch(result FilterResult) *TimeFilter {
+	f.onMatch = result
+	return f
+}
+
+// WithOnMismatch sets the result when the entry is outside all windows
+func (f *TimeFilter) WithOnMismatch(result FilterResult) *TimeFilter {
+	f.onMismatch = result
+	return f
+}
+
+// Decide implements Filter
+func (f *TimeFilter) Decide(entry *Entry) FilterResult {
+	t := entry.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	t = t.In(f.location)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+
+	for _, w := range f.windows {
+		if w.start <= w.end {
+			if offset >= w.start && offset < w.end {
+				return f.onMatch
+			}
+		} else if offset >= w.start || offset < w.end {
+			return f.onMatch
+		}
+	}
+	return f.onMismatch
+}
+
+// parseClock parses "15:04" or "15:04:05" into an offset from midnight
+func parseClock(s string) (time.Duration, error) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time of day %q", s)
+}
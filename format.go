@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SLF4J-style placeholders
+//
+// A format with {} and without any % is filled like SLF4J does, each {}
+// taking the next argument formatted as with %v, for teams coming from
+// Java:
+//
+//	log.Info("user {} logged in from {}", user, ip)
+//
+// \{} writes a literal {}, placeholders without an argument are kept and
+// arguments without a placeholder are ignored. A format with a % is always
+// a fmt format, so existing calls such as Info("empty config {} for %s",
+// tenant) keep their literal {}. Like % formats, the message is only built
+// once the entry passed the level check.
+
+// formatMessage builds the message from a % or {} format
+func formatMessage(format string, args []interface{}) string {
+	if !strings.Contains(format, "{}") || strings.Contains(format, "%") {
+		return fmt.Sprintf(format, args...)
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(format) + 16*len(args))
+	next := 0
+	for {
+		i := strings.Index(format, "{}")
+		if i < 0 {
+			sb.WriteString(format)
+			return sb.String()
+		}
+		if i > 0 && format[i-1] == '\\' {
+			sb.WriteString(format[:i-1])
+			sb.WriteString("{}")
+		} else if next < len(args) {
+			sb.WriteString(format[:i])
+			fmt.Fprint(&sb, args[next])
+			next++
+		} else {
+			sb.WriteString(format[:i+2])
+		}
+		format = format[i+2:]
+	}
+}
@@ -0,0 +1,30 @@
+package logger
+
+import "testing"
+
+// TestFormatMessage covers SLF4J-style {} placeholders and the fmt fallback
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		args   []interface{}
+		want   string
+	}{
+		{"no placeholders", "plain text", nil, "plain text"},
+		{"placeholders", "user {} logged in from {}", []interface{}{"bob", "10.0.0.1"}, "user bob logged in from 10.0.0.1"},
+		{"adjacent placeholders", "{}{}", []interface{}{1, 2}, "12"},
+		{"missing argument", "a={} b={}", []interface{}{1}, "a=1 b={}"},
+		{"extra argument", "a={}", []interface{}{1, 2}, "a=1"},
+		{"escaped placeholder", `literal \{} then {}`, []interface{}{"x"}, "literal {} then x"},
+		{"percent format keeps braces", "empty config {} for %s", []interface{}{"acme"}, "empty config {} for acme"},
+		{"percent format", "%d items", []interface{}{3}, "3 items"},
+		{"value formatting", "err: {}", []interface{}{[]int{1, 2}}, "err: [1 2]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatMessage(tt.format, tt.args); got != tt.want {
+				t.Errorf("formatMessage(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
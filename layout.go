@@ -5,8 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 // Layout formats log entries for output
@@ -14,128 +20,586 @@ type Layout interface {
 	Format(entry *Entry) []byte
 }
 
+// BufferedLayout is implemented by layouts that can format into a caller
+// supplied buffer, so appenders can reuse pooled buffers instead of
+// allocating a new []byte for every entry
+type BufferedLayout interface {
+	Layout
+	AppendFormat(buf *bytes.Buffer, entry *Entry)
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// maxPooledBuffer keeps the pool from pinning buffers grown by huge entries
+const maxPooledBuffer = 64 << 10
+
+// formatEntry formats entry into a pooled buffer, return it with freeBuffer
+func formatEntry(layout Layout, entry *Entry) *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	if bl, ok := layout.(BufferedLayout); ok {
+		bl.AppendFormat(buf, entry)
+	} else {
+		buf.Write(layout.Format(entry))
+	}
+	return buf
+}
+
+// freeBuffer returns a buffer obtained from formatEntry to the pool
+func freeBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
+
+// timeZone is the zone timestamps are formatted in, see SetTimeZone
+var timeZone atomic.Pointer[time.Location]
+
+// SetTimeZone formats timestamps in loc, e.g. time.UTC, rather than local
+// time, for layouts without a location of their own. nil restores local time.
+func SetTimeZone(loc *time.Location) {
+	timeZone.Store(loc)
+}
+
+// inZone returns t in loc, or in the zone set with SetTimeZone
+func inZone(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = timeZone.Load()
+	}
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
 // PatternLayout formats logs using a pattern string
 // Supported patterns:
 //
 //	%d{format} - date/time (Go time format)
 //	%p         - level
-//	%c         - logger name
+//	%c{n}      - logger name; with precision n only the last n dot separated
+//	             segments, -n drops the first n, and a dotted pattern such as
+//	             1. or 3.1.* shortens ancestors ("com.example.Service" with
+//	             %c{1.} is "c.e.Service", * keeps a segment whole)
 //	%m         - message
 //	%n         - newline
 //	%F         - file name
 //	%L         - line number
 //	%M         - method/function name
-//	%X{key}    - MDC value
+//	%X{key}    - MDC value, %X alone prints the whole MDC as k=v pairs
+//	%fields    - structured fields as k=v pairs
 //	%marker    - marker
+//	%code      - event code, see Logger.WithCode
+//	%sn        - sequence number, see Logger.SetSequence; also %sequenceNumber
+//	%ex{n}     - error with wrapped causes and stack, at most n frames per
+//	             error ("short" for the first line only, "none" to omit);
+//	             also %exception, %throwable, %stacktrace
+//	%pid       - process ID
+//	%hostname  - host name
+//	%exe       - executable name
+//	%goroutine - ID of the logging goroutine when recorded, see
+//	             Logger.SetGoroutineID, otherwise of the goroutine formatting
+//	             the entry (the logging goroutine unless the appender is
+//	             asynchronous)
+//	%replace{pattern}{regex}{replacement}
+//	           - the nested pattern with every regex match replaced,
+//	             e.g. %replace{%m}{[\r\n]+}{ } or %replace{%m}{\d{12,19}}{****}
+//	             an invalid regex writes [invalid %replace regex] instead
+//	             of the unmasked text, see Err
+//	%highlight{pattern}{LEVEL=style, ...}
+//	           - the nested pattern colored by level, e.g.
+//	             %highlight{%-5p}{ERROR=bold red, WARN=yellow, INFO=green}
+//	             styles combine color names (red, bright_blue, bg_white),
+//	             bold/dim/italic/underline and raw SGR codes like 38;5;208
+//	%%         - a literal percent sign
+//
+// Any converter accepts log4j-style format modifiers between the % and its
+// name: %-5p pads to 5 characters aligned left, %20c pads aligned right,
+// %.30m keeps at most the last 30 characters and %.-30m the first 30.
 type PatternLayout struct {
-	pattern string
-	parts   []patternPart
+	pattern  string
+	parts    []patternPart
+	location *time.Location
+	err      error // first invalid %replace regex
 }
 
 type patternPart struct {
 	literal  string
 	variable string
-	param    string
-}
+	param    string   // first parameter
+	params   []string // all {} parameters
 
-var patternRegex = regexp.MustCompile(`%(\w+)(?:\{([^}]+)\})?`)
+	sub    *PatternLayout // nested pattern of %replace and %highlight
+	regex  *regexp.Regexp
+	err    error // invalid regex of this or a nested part
+	colors map[Level]string
+	abbrev func(string) string // %c precision
+
+	leftAlign bool // pad on the right instead of the left
+	minWidth  int
+	maxWidth  int  // 0 means no limit
+	truncEnd  bool // truncate from the end instead of the beginning
+}
 
 // NewPatternLayout creates a new pattern layout
-// Example: "%d{2006-01-02 15:04:05.000} [%p] %c - %m%n"
+// Example: "%d{2006-01-02 15:04:05.000} [%-5p] %c - %m%n"
 func NewPatternLayout(pattern string) *PatternLayout {
 	pl := &PatternLayout{pattern: pattern}
 	pl.parse()
 	return pl
 }
 
+// Err returns the first invalid %replace regex of the pattern, nil if the
+// pattern is valid. Init refuses such patterns.
+func (p *PatternLayout) Err() error {
+	return p.err
+}
+
+// WithLocation formats %d in loc, e.g. time.UTC, instead of the zone set
+// with SetTimeZone
+func (p *PatternLayout) WithLocation(loc *time.Location) *PatternLayout {
+	p.location = loc
+	for _, part := range p.parts {
+		if part.sub != nil {
+			part.sub.WithLocation(loc)
+		}
+	}
+	return p
+}
+
 func (p *PatternLayout) parse() {
 	s := p.pattern
-	for {
-		loc := patternRegex.FindStringSubmatchIndex(s)
-		if loc == nil {
-			if len(s) > 0 {
-				p.parts = append(p.parts, patternPart{literal: s})
-			}
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			p.parts = append(p.parts, patternPart{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '%')
+		if i < 0 {
+			literal.WriteString(s)
 			break
 		}
+		literal.WriteString(s[:i])
+		s = s[i:]
 
-		// Add literal before match
-		if loc[0] > 0 {
-			p.parts = append(p.parts, patternPart{literal: s[:loc[0]]})
+		if strings.HasPrefix(s, "%%") {
+			literal.WriteByte('%')
+			s = s[2:]
+			continue
 		}
 
-		// Extract variable and optional param
-		variable := s[loc[2]:loc[3]]
-		param := ""
-		if loc[4] >= 0 && loc[5] >= 0 {
-			param = s[loc[4]:loc[5]]
+		part, n := parsePatternPart(s)
+		if n == 0 {
+			// Not a converter, keep the percent sign as text
+			literal.WriteByte('%')
+			s = s[1:]
+			continue
 		}
-		p.parts = append(p.parts, patternPart{variable: variable, param: param})
+		flush()
+		if p.err == nil {
+			p.err = part.err
+		}
+		p.parts = append(p.parts, part)
+		s = s[n:]
+	}
+	flush()
+}
+
+// parsePatternPart parses "%[-][min][.[-]max]name[{param}]" at the start of s,
+// returning the part and the number of bytes consumed (0 if s is no converter)
+func parsePatternPart(s string) (patternPart, int) {
+	var part patternPart
+	i := 1
 
-		s = s[loc[1]:]
+	if i < len(s) && s[i] == '-' {
+		part.leftAlign = true
+		i++
+	}
+	part.minWidth, i = parseDigits(s, i)
+	if i < len(s) && s[i] == '.' {
+		i++
+		if i < len(s) && s[i] == '-' {
+			part.truncEnd = true
+			i++
+		}
+		part.maxWidth, i = parseDigits(s, i)
+	}
+
+	start := i
+	for i < len(s) && isWordChar(s[i]) {
+		i++
+	}
+	if i == start {
+		return patternPart{}, 0
+	}
+	part.variable = s[start:i]
+
+	for i < len(s) && s[i] == '{' {
+		end := matchingBrace(s, i)
+		if end < 0 {
+			break
+		}
+		part.params = append(part.params, s[i+1:end])
+		i = end + 1
+	}
+	if len(part.params) > 0 {
+		part.param = part.params[0]
 	}
+
+	switch {
+	case part.variable == "replace" && len(part.params) == 3:
+		part.sub = NewPatternLayout(part.params[0])
+		part.err = part.sub.err
+		regex, err := regexp.Compile(part.params[1])
+		if err != nil {
+			part.err = fmt.Errorf("pattern: %%replace regex %q: %w", part.params[1], err)
+		}
+		part.regex = regex
+	case part.variable == "c" && part.param != "":
+		part.abbrev = parseNameAbbreviation(part.param)
+	case part.variable == "highlight" && len(part.params) > 0:
+		part.sub = NewPatternLayout(part.params[0])
+		part.colors = levelColors
+		if len(part.params) > 1 {
+			part.colors = parseLevelStyles(part.params[1])
+		}
+		part.err = part.sub.err
+	}
+	return part, i
+}
+
+// matchingBrace returns the index of the '}' closing the '{' at open, or -1
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseDigits(s string, i int) (int, int) {
+	n := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		n = n*10 + int(s[i]-'0')
+		i++
+	}
+	return n, i
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
 }
 
 // Format applies the pattern to an entry
 func (p *PatternLayout) Format(entry *Entry) []byte {
 	var buf bytes.Buffer
+	p.AppendFormat(&buf, entry)
+	return buf.Bytes()
+}
 
+// AppendFormat implements BufferedLayout
+func (p *PatternLayout) AppendFormat(buf *bytes.Buffer, entry *Entry) {
+	p.appendColored(buf, entry, nil, "")
+}
+
+// segmentConverters maps the converters to the ColoredLayout segment
+// they belong to
+var segmentConverters = map[string]string{
+	"p":      "level",
+	"c":      "logger",
+	"m":      "message",
+	"marker": "marker",
+}
+
+// appendColored formats the entry, wrapping the converters of the given
+// segments in color, padding included
+func (p *PatternLayout) appendColored(buf *bytes.Buffer, entry *Entry, segments []string, color string) {
 	for _, part := range p.parts {
 		if part.literal != "" {
 			buf.WriteString(part.literal)
 			continue
 		}
 
-		switch part.variable {
-		case "d":
-			format := "2006-01-02 15:04:05.000"
-			if part.param != "" {
-				format = part.param
-			}
-			buf.WriteString(entry.Time.Format(format))
-		case "p":
-			buf.WriteString(entry.Level.String())
-		case "c":
+		colored := color != "" && slices.Contains(segments, segmentConverters[part.variable])
+		if colored {
+			buf.WriteString(color)
+		}
+		start := buf.Len()
+		p.convert(buf, part, entry)
+		if part.minWidth > 0 || part.maxWidth > 0 {
+			part.adjust(buf, start)
+		}
+		if colored {
+			buf.WriteString(colorReset)
+		}
+	}
+}
+
+// convert writes the value of a single converter
+func (p *PatternLayout) convert(buf *bytes.Buffer, part patternPart, entry *Entry) {
+	switch part.variable {
+	case "d":
+		format := "2006-01-02 15:04:05.000"
+		if part.param != "" {
+			format = part.param
+		}
+		buf.WriteString(inZone(entry.Time, p.location).Format(format))
+	case "p":
+		buf.WriteString(entry.Level.String())
+	case "c":
+		if part.abbrev != nil {
+			buf.WriteString(part.abbrev(entry.Logger))
+		} else {
 			buf.WriteString(entry.Logger)
-		case "m":
-			buf.WriteString(entry.Message)
-		case "n":
-			buf.WriteString("\n")
-		case "F":
-			buf.WriteString(entry.Caller.File)
-		case "L":
-			buf.WriteString(fmt.Sprintf("%d", entry.Caller.Line))
-		case "M":
-			buf.WriteString(entry.Caller.Function)
-		case "marker":
-			buf.WriteString(entry.Marker)
-		case "X":
-			if part.param != "" {
-				if val, ok := entry.Context[part.param]; ok {
-					buf.WriteString(fmt.Sprintf("%v", val))
+		}
+	case "m":
+		buf.WriteString(entry.Message)
+	case "n":
+		buf.WriteString("\n")
+	case "F":
+		buf.WriteString(entry.Caller.File)
+	case "L":
+		buf.WriteString(fmt.Sprintf("%d", entry.Caller.Line))
+	case "M":
+		buf.WriteString(entry.Caller.Function)
+	case "marker":
+		buf.WriteString(entry.Marker)
+	case "code":
+		buf.WriteString(entry.Code)
+	case "sn", "sequenceNumber":
+		buf.WriteString(strconv.FormatUint(entry.Seq, 10))
+	case "X":
+		if part.param == "" {
+			writeKeyValues(buf, entry.Context)
+		} else if val, ok := entry.Context[part.param]; ok {
+			buf.WriteString(fmt.Sprintf("%v", val))
+		}
+	case "fields":
+		writeKeyValues(buf, entry.Fields)
+		writeTypedFields(buf, entry.Typed, len(entry.Fields) > 0)
+	case "ex", "exception", "throwable", "stacktrace":
+		writePatternError(buf, entry, part.param)
+	case "pid":
+		buf.WriteString(pidString)
+	case "hostname":
+		buf.WriteString(cachedHostname())
+	case "exe":
+		buf.WriteString(cachedExecutable())
+	case "goroutine":
+		id := entry.Goroutine
+		if id == 0 {
+			id = goroutineID()
+		}
+		buf.WriteString(strconv.FormatUint(id, 10))
+	case "replace":
+		if part.sub == nil {
+			return
+		}
+		// Never leak the text a broken regex was meant to mask
+		if part.regex == nil {
+			buf.WriteString("[invalid %replace regex]")
+			return
+		}
+		text := part.sub.Format(entry)
+		buf.Write(part.regex.ReplaceAll(text, []byte(part.params[2])))
+	case "highlight":
+		if part.sub == nil {
+			return
+		}
+		color := part.colors[entry.Level]
+		buf.WriteString(color)
+		part.sub.AppendFormat(buf, entry)
+		if color != "" {
+			buf.WriteString(colorReset)
+		}
+	case "t":
+		buf.WriteString(fmt.Sprintf("%d", time.Now().UnixNano()))
+	default:
+		buf.WriteString("%" + part.variable)
+	}
+}
+
+// adjust applies the width modifiers to the text written since start
+func (part patternPart) adjust(buf *bytes.Buffer, start int) {
+	text := buf.Bytes()[start:]
+	width := utf8.RuneCount(text)
+
+	if part.maxWidth > 0 && width > part.maxWidth {
+		var kept []byte
+		if part.truncEnd {
+			end := 0
+			for n := 0; n < part.maxWidth; n++ {
+				_, size := utf8.DecodeRune(text[end:])
+				end += size
+			}
+			kept = text[:end]
+		} else {
+			begin := len(text)
+			for n := 0; n < part.maxWidth; n++ {
+				_, size := utf8.DecodeLastRune(text[:begin])
+				begin -= size
+			}
+			kept = text[begin:]
+		}
+		kept = append([]byte(nil), kept...)
+		buf.Truncate(start)
+		buf.Write(kept)
+		width = part.maxWidth
+	}
+
+	if pad := part.minWidth - width; pad > 0 {
+		if part.leftAlign {
+			buf.WriteString(strings.Repeat(" ", pad))
+			return
+		}
+		text := append([]byte(nil), buf.Bytes()[start:]...)
+		buf.Truncate(start)
+		buf.WriteString(strings.Repeat(" ", pad))
+		buf.Write(text)
+	}
+}
+
+// parseNameAbbreviation compiles a %c precision into an abbreviator
+func parseNameAbbreviation(spec string) func(string) string {
+	if n, err := strconv.Atoi(spec); err == nil {
+		if n < 0 {
+			return func(name string) string {
+				segments := strings.SplitN(name, ".", -n+1)
+				if len(segments) <= -n {
+					return name
 				}
+				return segments[len(segments)-1]
 			}
-		case "t":
-			buf.WriteString(fmt.Sprintf("%d", time.Now().UnixNano()))
-		default:
-			buf.WriteString("%" + part.variable)
+		}
+		return func(name string) string {
+			if n == 0 {
+				return ""
+			}
+			end := len(name)
+			for i := 0; i < n; i++ {
+				end = strings.LastIndexByte(name[:end], '.')
+				if end < 0 {
+					return name
+				}
+			}
+			return name[end+1:]
 		}
 	}
 
-	return buf.Bytes()
+	// Dotted pattern: each element is the number of characters kept of the
+	// matching ancestor segment, or * to keep it whole. The last element
+	// repeats, the final segment is never shortened.
+	elements := strings.Split(strings.TrimSuffix(spec, "."), ".")
+	keep := make([]int, len(elements))
+	for i, e := range elements {
+		if e == "*" {
+			keep[i] = -1
+		} else if n, err := strconv.Atoi(e); err == nil && n > 0 {
+			keep[i] = n
+		} else {
+			keep[i] = 1
+		}
+	}
+	return func(name string) string {
+		segments := strings.Split(name, ".")
+		for i := 0; i < len(segments)-1; i++ {
+			k := keep[len(keep)-1]
+			if i < len(keep) {
+				k = keep[i]
+			}
+			if k >= 0 && len(segments[i]) > k {
+				segments[i] = segments[i][:k]
+			}
+		}
+		return strings.Join(segments, ".")
+	}
+}
+
+// writeKeyValues writes a map as space separated key=value pairs in key
+// order, quoting values that contain spaces, quotes or '='
+func writeKeyValues(buf *bytes.Buffer, values map[string]interface{}) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(quoteIfNeeded(fmt.Sprint(values[k])))
+	}
+}
+
+// writePatternError renders the error and captured stack for %ex, each
+// line ends with a newline
+func writePatternError(buf *bytes.Buffer, entry *Entry, param string) {
+	err := entry.Error
+	if err == nil && len(entry.Stack) == 0 {
+		return
+	}
+	switch param {
+	case "none":
+		return
+	case "short":
+		if err == nil {
+			return
+		}
+		msg := err.Error()
+		if i := strings.IndexByte(msg, '\n'); i >= 0 {
+			msg = msg[:i]
+		}
+		buf.WriteString(msg)
+		buf.WriteByte('\n')
+		return
+	}
+	maxFrames := -1
+	if n, convErr := strconv.Atoi(param); convErr == nil && n >= 0 {
+		maxFrames = n
+	}
+	if err != nil {
+		writeError(buf, err, maxFrames)
+	}
+	writeStack(buf, entry.Stack, maxFrames)
 }
 
 // JSONLayout formats logs as JSON
 type JSONLayout struct {
-	Pretty     bool
-	TimeFormat string
+	Pretty       bool
+	TimeFormat   string
+	Location     *time.Location         // zone of the timestamp, nil uses SetTimeZone
+	Keys         map[string]string      // standard key -> output key, e.g. "message" -> "msg"
+	StaticFields map[string]interface{} // added to every entry
+	NestFields   bool                   // write Fields under "fields" instead of the top level
+	OmitEmpty    bool                   // skip standard keys with empty values
+	Ordered      bool                   // standard keys in fixed order, then custom keys sorted
+	ErrorChain   bool                   // write error as an object with its wrapped causes
 }
 
 // NewJSONLayout creates a new JSON layout
 func NewJSONLayout() *JSONLayout {
 	return &JSONLayout{
-		Pretty:     false,
-		TimeFormat: time.RFC3339Nano,
+		Pretty:       false,
+		TimeFormat:   time.RFC3339Nano,
+		Keys:         make(map[string]string),
+		StaticFields: make(map[string]interface{}),
 	}
 }
 
@@ -151,67 +615,299 @@ func (j *JSONLayout) WithTimeFormat(format string) *JSONLayout {
 	return j
 }
 
+// WithNestedFields places Fields under a nested "fields" object
+func (j *JSONLayout) WithNestedFields(nested bool) *JSONLayout {
+	j.NestFields = nested
+	return j
+}
+
+// WithErrorChain writes the error as an object instead of its message:
+//
+//	"error": {"type": "*fmt.wrapError", "message": "load config: open app.yaml: no such file",
+//	          "causes": [{"type": "*fs.PathError", "message": "open app.yaml: no such file", "stack": "..."}]}
+//
+// Every link of the unwrap chain has its type, message and, when recorded,
+// its stack, so root causes aren't lost behind fmt wrapping
+func (j *JSONLayout) WithErrorChain(chain bool) *JSONLayout {
+	j.ErrorChain = chain
+	return j
+}
+
+// WithLocation formats the timestamp in loc, e.g. time.UTC
+func (j *JSONLayout) WithLocation(loc *time.Location) *JSONLayout {
+	j.Location = loc
+	return j
+}
+
+// WithOmitEmpty skips standard keys with empty values, e.g. file and line
+// when caller capture is off
+func (j *JSONLayout) WithOmitEmpty(omit bool) *JSONLayout {
+	j.OmitEmpty = omit
+	return j
+}
+
+// WithOrdered writes standard keys in a fixed order (timestamp, seq, level,
+// logger, message, marker, event_code, goroutine, file, line, error,
+// context, fields) followed by the remaining keys sorted, for stable diffs
+// and tests
+func (j *JSONLayout) WithOrdered(ordered bool) *JSONLayout {
+	j.Ordered = ordered
+	return j
+}
+
+// WithKey renames a standard key (timestamp, seq, level, logger, message,
+// file, line, marker, event_code, goroutine, context, error, fields,
+// trace_id, span_id, trace_flags), e.g. WithKey("message", "msg")
+func (j *JSONLayout) WithKey(key, name string) *JSONLayout {
+	return j.WithKeys(map[string]string{key: name})
+}
+
+// WithKeys renames several standard keys
+func (j *JSONLayout) WithKeys(keys map[string]string) *JSONLayout {
+	// A zero-value &JSONLayout{} has no map yet
+	if j.Keys == nil && len(keys) > 0 {
+		j.Keys = make(map[string]string, len(keys))
+	}
+	for k, v := range keys {
+		j.Keys[k] = v
+	}
+	return j
+}
+
+// WithStaticField adds a field written on every entry, e.g. service or env
+func (j *JSONLayout) WithStaticField(key string, value interface{}) *JSONLayout {
+	return j.WithStaticFields(map[string]interface{}{key: value})
+}
+
+// WithStaticFields adds several static fields
+func (j *JSONLayout) WithStaticFields(fields map[string]interface{}) *JSONLayout {
+	if j.StaticFields == nil && len(fields) > 0 {
+		j.StaticFields = make(map[string]interface{}, len(fields))
+	}
+	for k, v := range fields {
+		j.StaticFields[k] = v
+	}
+	return j
+}
+
+// key returns the output name of a standard key
+func (j *JSONLayout) key(name string) string {
+	if renamed, ok := j.Keys[name]; ok && renamed != "" {
+		return renamed
+	}
+	return name
+}
+
 // Format converts entry to JSON
 func (j *JSONLayout) Format(entry *Entry) []byte {
-	data := map[string]interface{}{
-		"timestamp": entry.Time.Format(j.TimeFormat),
-		"level":     entry.Level.String(),
-		"logger":    entry.Logger,
-		"message":   entry.Message,
-		"file":      entry.Caller.File,
-		"line":      entry.Caller.Line,
+	var buf bytes.Buffer
+	j.AppendFormat(&buf, entry)
+	return buf.Bytes()
+}
+
+// AppendFormat implements BufferedLayout
+func (j *JSONLayout) AppendFormat(buf *bytes.Buffer, entry *Entry) {
+	data := make(map[string]interface{}, len(j.StaticFields)+len(entry.Fields)+len(entry.Typed)+8)
+	for k, v := range j.StaticFields {
+		data[k] = v
+	}
+
+	data[j.key("timestamp")] = inZone(entry.Time, j.Location).Format(j.TimeFormat)
+	data[j.key("level")] = entry.Level.String()
+	if !j.OmitEmpty || entry.Logger != "" {
+		data[j.key("logger")] = entry.Logger
+	}
+	if !j.OmitEmpty || entry.Message != "" {
+		data[j.key("message")] = entry.Message
+	}
+	if !j.OmitEmpty || entry.Caller.File != "" {
+		data[j.key("file")] = entry.Caller.File
+		data[j.key("line")] = entry.Caller.Line
 	}
 
 	if entry.Marker != "" {
-		data["marker"] = entry.Marker
+		data[j.key("marker")] = entry.Marker
+	}
+	if entry.Code != "" {
+		data[j.key("event_code")] = entry.Code
+	}
+	if entry.Seq != 0 {
+		data[j.key("seq")] = entry.Seq
+	}
+	if entry.Goroutine != 0 {
+		data[j.key("goroutine")] = entry.Goroutine
 	}
 
 	if len(entry.Context) > 0 {
-		data["context"] = entry.Context
+		// trace correlation keys are written top level, see SetSpanContextFunc
+		context, copied := entry.Context, false
+		for _, k := range traceContextKeys {
+			v, ok := entry.Context[k]
+			if !ok {
+				continue
+			}
+			if !copied {
+				context, copied = make(map[string]interface{}, len(entry.Context)), true
+				for ck, cv := range entry.Context {
+					context[ck] = cv
+				}
+			}
+			data[j.key(k)] = v
+			delete(context, k)
+		}
+		if len(context) > 0 {
+			data[j.key("context")] = context
+		}
 	}
 
-	if len(entry.Fields) > 0 {
-		for k, v := range entry.Fields {
-			data[k] = v
+	if entry.HasFields() {
+		if j.NestFields {
+			data[j.key("fields")] = entry.FieldMap()
+		} else {
+			for k, v := range entry.Fields {
+				data[k] = v
+			}
+			for _, f := range entry.Typed {
+				if f.Key != "" {
+					data[f.Key] = f.Value()
+				}
+			}
 		}
 	}
 
 	if entry.Error != nil {
-		data["error"] = entry.Error.Error()
+		if j.ErrorChain {
+			data[j.key("error")] = jsonErrorChain(entry.Error)
+		} else {
+			data[j.key("error")] = entry.Error.Error()
+		}
+	}
+	if len(entry.Stack) > 0 {
+		data[j.key("stack_trace")] = formatStack(entry.Stack)
 	}
 
-	var result []byte
 	var err error
-	if j.Pretty {
-		result, err = json.MarshalIndent(data, "", "  ")
+	if j.Ordered {
+		var result []byte
+		if result, err = j.marshalOrdered(data); err == nil {
+			buf.Write(result)
+			buf.WriteByte('\n')
+		}
 	} else {
-		result, err = json.Marshal(data)
+		// Encode writes nothing on error and appends the newline itself
+		enc := json.NewEncoder(buf)
+		if j.Pretty {
+			enc.SetIndent("", "  ")
+		}
+		err = enc.Encode(data)
 	}
 
 	if err != nil {
-		return []byte(fmt.Sprintf(`{"error":"marshal failed: %v"}`, err))
+		fmt.Fprintf(buf, `{"error":"marshal failed: %v"}`, err)
 	}
+}
 
-	return append(result, '\n')
+// jsonErrorChain describes err with the errors it wraps under "causes"
+func jsonErrorChain(err error) map[string]interface{} {
+	var root map[string]interface{}
+	var causes []interface{}
+	var last []uintptr
+	for i, e := range errorChain(err) {
+		link := map[string]interface{}{
+			"type":    fmt.Sprintf("%T", e),
+			"message": e.Error(),
+		}
+		// wrappers often record the same stack as the error they wrap
+		if pcs := stackOf(e); len(pcs) > 0 && !sameStack(pcs, last) {
+			link["stack"] = formatStack(pcs)
+			last = pcs
+		}
+		if i == 0 {
+			root = link
+		} else {
+			causes = append(causes, link)
+		}
+	}
+	if len(causes) > 0 {
+		root["causes"] = causes
+	}
+	return root
+}
+
+// jsonStandardKeys is the output order of standard keys in ordered mode
+var jsonStandardKeys = []string{"timestamp", "seq", "level", "logger", "message", "marker", "event_code", "goroutine", "file", "line", "error", "stack_trace", "trace_id", "span_id", "trace_flags", "context", "fields"}
+
+// marshalOrdered encodes data with standard keys first, then sorted keys
+func (j *JSONLayout) marshalOrdered(data map[string]interface{}) ([]byte, error) {
+	keys := make([]string, 0, len(data))
+	seen := make(map[string]bool, len(jsonStandardKeys))
+	for _, name := range jsonStandardKeys {
+		k := j.key(name)
+		if _, ok := data[k]; ok && !seen[k] {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+	custom := len(keys)
+	for k := range data {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[custom:])
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(k)
+		value, err := json.Marshal(data[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	if !j.Pretty {
+		return buf.Bytes(), nil
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return pretty.Bytes(), nil
 }
 
 // TextLayout is a simple text formatter
 type TextLayout struct {
 	TimeFormat string
+	Location   *time.Location // zone of the time, nil uses SetTimeZone
 	ShowCaller bool
 	ShowLevel  bool
 	LevelWidth int
 	Separator  string
+
+	ShowFields  bool // append Fields as key=value pairs
+	ShowContext bool // append MDC context as key=value pairs
+	ShowError   bool // print the error and its causes on the following lines
 }
 
 // NewTextLayout creates a simple text layout
 func NewTextLayout() *TextLayout {
 	return &TextLayout{
-		TimeFormat: "2006/01/02 15:04:05.000",
-		ShowCaller: true,
-		ShowLevel:  true,
-		LevelWidth: 5,
-		Separator:  " ",
+		TimeFormat:  "2006/01/02 15:04:05.000",
+		ShowCaller:  true,
+		ShowLevel:   true,
+		LevelWidth:  5,
+		Separator:   " ",
+		ShowFields:  true,
+		ShowContext: true,
+		ShowError:   true,
 	}
 }
 
@@ -221,71 +917,266 @@ func (t *TextLayout) WithTimeFormat(format string) *TextLayout {
 	return t
 }
 
+// WithLocation formats the time in loc, e.g. time.UTC
+func (t *TextLayout) WithLocation(loc *time.Location) *TextLayout {
+	t.Location = loc
+	return t
+}
+
 // WithCaller enables/disables caller info
 func (t *TextLayout) WithCaller(show bool) *TextLayout {
 	t.ShowCaller = show
 	return t
 }
 
+// WithFields enables/disables structured fields
+func (t *TextLayout) WithFields(show bool) *TextLayout {
+	t.ShowFields = show
+	return t
+}
+
+// WithContext enables/disables MDC context
+func (t *TextLayout) WithContext(show bool) *TextLayout {
+	t.ShowContext = show
+	return t
+}
+
+// WithError enables/disables error output
+func (t *TextLayout) WithError(show bool) *TextLayout {
+	t.ShowError = show
+	return t
+}
+
 // Format converts entry to text
 func (t *TextLayout) Format(entry *Entry) []byte {
-	var parts []string
+	var buf bytes.Buffer
+	t.AppendFormat(&buf, entry)
+	return buf.Bytes()
+}
 
+// AppendFormat implements BufferedLayout
+func (t *TextLayout) AppendFormat(buf *bytes.Buffer, entry *Entry) {
 	// Timestamp
-	parts = append(parts, entry.Time.Format(t.TimeFormat))
+	var scratch [64]byte
+	buf.Write(inZone(entry.Time, t.Location).AppendFormat(scratch[:0], t.TimeFormat))
 
 	// Caller
 	if t.ShowCaller {
-		parts = append(parts, fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line))
+		buf.WriteString(t.Separator)
+		buf.WriteString(entry.Caller.File)
+		buf.WriteByte(':')
+		buf.Write(strconv.AppendInt(scratch[:0], int64(entry.Caller.Line), 10))
 	}
 
 	// Level
 	if t.ShowLevel {
-		level := entry.Level.String()
-		if t.LevelWidth > 0 {
-			level = fmt.Sprintf("%-*s", t.LevelWidth, level)
-		}
-		parts = append(parts, "["+strings.TrimSpace(level)+"]")
+		buf.WriteString(t.Separator)
+		buf.WriteByte('[')
+		buf.WriteString(entry.Level.String())
+		buf.WriteByte(']')
 	}
 
 	// Marker
 	if entry.Marker != "" {
-		parts = append(parts, "["+entry.Marker+"]")
+		buf.WriteString(t.Separator)
+		buf.WriteByte('[')
+		buf.WriteString(entry.Marker)
+		buf.WriteByte(']')
 	}
 
 	// Message
-	parts = append(parts, entry.Message)
+	buf.WriteString(t.Separator)
+	buf.WriteString(entry.Message)
+
+	// Context and fields
+	if t.ShowContext && len(entry.Context) > 0 {
+		buf.WriteString(t.Separator)
+		writeKeyValues(buf, entry.Context)
+	}
+	if t.ShowFields && entry.HasFields() {
+		buf.WriteString(t.Separator)
+		writeKeyValues(buf, entry.Fields)
+		writeTypedFields(buf, entry.Typed, len(entry.Fields) > 0)
+	}
+	buf.WriteByte('\n')
 
-	return []byte(strings.Join(parts, t.Separator) + "\n")
+	if t.ShowError && entry.Error != nil {
+		writeError(buf, entry.Error, -1)
+	}
+	if t.ShowError && len(entry.Stack) > 0 {
+		writeStack(buf, entry.Stack, -1)
+	}
 }
 
 // ColoredLayout adds ANSI colors to text output
+// By default the whole line is colored, WithSegments limits coloring to
+// parts of the entry such as the level token.
 type ColoredLayout struct {
-	inner Layout
+	inner    Layout
+	colors   map[Level]string
+	segments []string
 }
 
 // NewColoredLayout wraps a layout with colors
 func NewColoredLayout(inner Layout) *ColoredLayout {
-	return &ColoredLayout{inner: inner}
+	colors := make(map[Level]string, len(levelColors))
+	for level, color := range levelColors {
+		colors[level] = color
+	}
+	return &ColoredLayout{inner: inner, colors: colors}
+}
+
+// Built-in themes for WithTheme
+var (
+	// ThemeDark suits terminals with a dark background
+	ThemeDark = map[Level]string{
+		TRACE: "gray",
+		DEBUG: "cyan",
+		INFO:  "bright_green",
+		WARN:  "bright_yellow",
+		ERROR: "bold bright_red",
+		PANIC: "bold bright_white bg_magenta",
+		FATAL: "bold bright_white bg_red",
+	}
+	// ThemeLight suits terminals with a light background
+	ThemeLight = map[Level]string{
+		TRACE: "color245",
+		DEBUG: "blue",
+		INFO:  "green",
+		WARN:  "color130",
+		ERROR: "bold red",
+		PANIC: "bold white bg_magenta",
+		FATAL: "bold white bg_red",
+	}
+)
+
+// WithTheme sets the style of each level listed in theme, styles combine
+// color names, bold/dim/underline, 256-color codes ("color208"), truecolor
+// ("#ff8800", "bg_#202020") and raw SGR codes
+func (c *ColoredLayout) WithTheme(theme map[Level]string) *ColoredLayout {
+	for level, style := range theme {
+		c.colors[level] = ansiStyle(style)
+	}
+	return c
+}
+
+// WithLevelColor sets the style of a single level, e.g. "bold #ff5f00"
+func (c *ColoredLayout) WithLevelColor(level Level, style string) *ColoredLayout {
+	c.colors[level] = ansiStyle(style)
+	return c
+}
+
+// WithSegments colors only the given parts of the output instead of the
+// whole line: "level", "logger", "message", "marker". The segments are the
+// matching converters of a PatternLayout (%p, %c, %m, %marker), other
+// layouts are still colored as a whole.
+func (c *ColoredLayout) WithSegments(segments ...string) *ColoredLayout {
+	c.segments = segments
+	return c
 }
 
 var levelColors = map[Level]string{
-	TRACE: "\033[90m", // Gray
-	DEBUG: "\033[36m", // Cyan
-	INFO:  "\033[32m", // Green
-	WARN:  "\033[33m", // Yellow
-	ERROR: "\033[31m", // Red
-	FATAL: "\033[35m", // Magenta
+	TRACE: "\033[90m",   // Gray
+	DEBUG: "\033[36m",   // Cyan
+	INFO:  "\033[32m",   // Green
+	WARN:  "\033[33m",   // Yellow
+	ERROR: "\033[31m",   // Red
+	PANIC: "\033[1;35m", // Bold magenta
+	FATAL: "\033[35m",   // Magenta
 }
 
 const colorReset = "\033[0m"
 
+var ansiCodes = map[string]string{
+	"bold":      "1",
+	"dim":       "2",
+	"italic":    "3",
+	"underline": "4",
+	"blink":     "5",
+	"reverse":   "7",
+}
+
+func init() {
+	for i, name := range []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"} {
+		ansiCodes[name] = strconv.Itoa(30 + i)
+		ansiCodes["bright_"+name] = strconv.Itoa(90 + i)
+		ansiCodes["bg_"+name] = strconv.Itoa(40 + i)
+		ansiCodes["bg_bright_"+name] = strconv.Itoa(100 + i)
+	}
+	ansiCodes["gray"] = ansiCodes["bright_black"]
+}
+
+// ansiStyle converts a style such as "bold red", "color208", "#ff8800" or
+// "38;5;208" to an escape sequence, unknown words are ignored
+func ansiStyle(style string) string {
+	var codes []string
+	for _, word := range strings.Fields(strings.ToLower(style)) {
+		if code, ok := ansiCodes[word]; ok {
+			codes = append(codes, code)
+		} else if code, ok := ansiExtendedColor(word); ok {
+			codes = append(codes, code)
+		} else if strings.Trim(word, "0123456789;") == "" {
+			codes = append(codes, word)
+		}
+	}
+	if len(codes) == 0 {
+		return ""
+	}
+	return "\033[" + strings.Join(codes, ";") + "m"
+}
+
+// ansiExtendedColor parses 256-color ("color208", "bg_color208") and
+// truecolor ("#ff8800", "bg_#ff8800") words
+func ansiExtendedColor(word string) (string, bool) {
+	prefix := "38"
+	if strings.HasPrefix(word, "bg_") {
+		prefix, word = "48", word[3:]
+	}
+	if n, ok := strings.CutPrefix(word, "color"); ok {
+		if v, err := strconv.Atoi(n); err == nil && v >= 0 && v <= 255 {
+			return prefix + ";5;" + n, true
+		}
+		return "", false
+	}
+	if hex, ok := strings.CutPrefix(word, "#"); ok && len(hex) == 6 {
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("%s;2;%d;%d;%d", prefix, rgb>>16, rgb>>8&0xff, rgb&0xff), true
+	}
+	return "", false
+}
+
+// parseLevelStyles parses "ERROR=bold red, WARN=yellow" into escape sequences,
+// levels not listed keep their default color
+func parseLevelStyles(spec string) map[Level]string {
+	colors := make(map[Level]string, len(levelColors))
+	for level, color := range levelColors {
+		colors[level] = color
+	}
+	for _, item := range strings.Split(spec, ",") {
+		name, style, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if level, ok := levelValues[strings.ToUpper(strings.TrimSpace(name))]; ok {
+			colors[level] = ansiStyle(style)
+		}
+	}
+	return colors
+}
+
 // Format adds color codes
 func (c *ColoredLayout) Format(entry *Entry) []byte {
-	result := c.inner.Format(entry)
-	color := levelColors[entry.Level]
-	if color != "" {
-		return []byte(color + string(result) + colorReset)
+	color := c.colors[entry.Level]
+	if color == "" {
+		return c.inner.Format(entry)
+	}
+	if pattern, ok := c.inner.(*PatternLayout); ok && len(c.segments) > 0 {
+		var buf bytes.Buffer
+		pattern.appendColored(&buf, entry, c.segments, color)
+		return buf.Bytes()
 	}
-	return result
+	return []byte(color + string(c.inner.Format(entry)) + colorReset)
 }
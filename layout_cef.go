@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CEFLayout formats logs in ArcSight Common Event Format for SIEM export:
+//
+//	CEF:0|Vendor|Product|Version|SignatureID|Name|Severity|Extension
+//
+// The signature ID is the marker (or logger name), the name is the first
+// line of the message. Fields and MDC context are mapped to extension keys.
+type CEFLayout struct {
+	Vendor          string
+	Product         string
+	Version         string
+	Mapping         map[string]string // field/context key -> CEF extension key
+	IncludeUnmapped bool              // emit unmapped fields under their own key
+}
+
+// NewCEFLayout creates a CEF layout for the given device
+func NewCEFLayout(vendor, product, version string) *CEFLayout {
+	return &CEFLayout{
+		Vendor:          vendor,
+		Product:         product,
+		Version:         version,
+		Mapping:         make(map[string]string),
+		IncludeUnmapped: true,
+	}
+}
+
+// WithMapping maps a field or context key to a CEF extension key
+// e.g. WithMapping("client_ip", "src")
+func (c *CEFLayout) WithMapping(field, key string) *CEFLayout {
+	c.Mapping[field] = key
+	return c
+}
+
+// WithIncludeUnmapped sets whether fields without a mapping are emitted
+func (c *CEFLayout) WithIncludeUnmapped(include bool) *CEFLayout {
+	c.IncludeUnmapped = include
+	return c
+}
+
+// cefSeverity maps a level to the CEF 0-10 severity scale
+func cefSeverity(level Level) int {
+	switch level {
+	case TRACE:
+		return 0
+	case DEBUG:
+		return 1
+	case INFO:
+		return 3
+	case WARN:
+		return 6
+	case ERROR:
+		return 8
+	case PANIC:
+		return 9
+	case FATAL:
+		return 10
+	}
+	return 3
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+// Format converts entry to a CEF line
+func (c *CEFLayout) Format(entry *Entry) []byte {
+	signature := entry.Marker
+	if signature == "" {
+		signature = entry.Logger
+	}
+	name := entry.Message
+	if i := strings.IndexByte(name, '\n'); i >= 0 {
+		name = name[:i]
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscaper.Replace(c.Vendor),
+		cefHeaderEscaper.Replace(c.Product),
+		cefHeaderEscaper.Replace(c.Version),
+		cefHeaderEscaper.Replace(signature),
+		cefHeaderEscaper.Replace(name),
+		cefSeverity(entry.Level),
+	)
+
+	ext := map[string]string{
+		"rt":  strconv.FormatInt(entry.Time.UnixMilli(), 10),
+		"msg": entry.Message,
+	}
+	if entry.Logger != "" {
+		ext["cs1Label"] = "logger"
+		ext["cs1"] = entry.Logger
+	}
+	if entry.Error != nil {
+		ext["reason"] = entry.Error.Error()
+	}
+	for k, v := range entry.Context {
+		c.addExtension(ext, k, v)
+	}
+	for k, v := range entry.FieldMap() {
+		c.addExtension(ext, k, v)
+	}
+
+	keys := make([]string, 0, len(ext))
+	for k := range ext {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(cefExtensionEscaper.Replace(ext[k]))
+	}
+	buf.WriteByte('\n')
+	return []byte(buf.String())
+}
+
+// addExtension adds a field under its mapped CEF key
+func (c *CEFLayout) addExtension(ext map[string]string, key string, value interface{}) {
+	if mapped, ok := c.Mapping[key]; ok {
+		key = mapped
+	} else if !c.IncludeUnmapped {
+		return
+	}
+	// Extension keys cannot contain separators
+	key = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '=' || r == '|' {
+			return '_'
+		}
+		return r
+	}, key)
+	ext[key] = fmt.Sprint(value)
+}
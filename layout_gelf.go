@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GELFLayout formats logs as GELF 1.1 messages (Graylog Extended Log Format)
+// Fields and MDC context become additional "_" prefixed fields.
+type GELFLayout struct {
+	Host      string
+	Delimiter byte // '\n' for files, 0 for GELF over TCP
+}
+
+// NewGELFLayout creates a GELF layout using the local hostname
+func NewGELFLayout() *GELFLayout {
+	host, _ := os.Hostname()
+	return &GELFLayout{
+		Host:      host,
+		Delimiter: '\n',
+	}
+}
+
+// WithHost overrides the reported host
+func (g *GELFLayout) WithHost(host string) *GELFLayout {
+	g.Host = host
+	return g
+}
+
+// WithDelimiter sets the byte written after each message
+func (g *GELFLayout) WithDelimiter(delimiter byte) *GELFLayout {
+	g.Delimiter = delimiter
+	return g
+}
+
+// syslogSeverity maps a level to its RFC 5424 severity
+func syslogSeverity(level Level) int {
+	switch level {
+	case TRACE, DEBUG:
+		return 7 // Debug
+	case INFO:
+		return 6 // Informational
+	case WARN:
+		return 4 // Warning
+	case ERROR:
+		return 3 // Error
+	case PANIC, FATAL:
+		return 2 // Critical
+	}
+	return 6
+}
+
+// Format converts entry to GELF JSON
+func (g *GELFLayout) Format(entry *Entry) []byte {
+	short := entry.Message
+	if i := strings.IndexByte(short, '\n'); i >= 0 {
+		short = short[:i]
+	}
+
+	data := map[string]interface{}{
+		"version":       "1.1",
+		"host":          g.Host,
+		"short_message": short,
+		"timestamp":     float64(entry.Time.UnixNano()) / 1e9,
+		"level":         syslogSeverity(entry.Level),
+		"_logger":       entry.Logger,
+		"_level_name":   entry.Level.String(),
+	}
+
+	full := entry.Message
+	if entry.Error != nil {
+		full += "\n" + entry.Error.Error()
+		data["_error"] = entry.Error.Error()
+	}
+	if full != short {
+		data["full_message"] = full
+	}
+
+	if entry.Marker != "" {
+		data["_marker"] = entry.Marker
+	}
+	if entry.Caller.File != "" {
+		data["_file"] = entry.Caller.File
+		data["_line"] = entry.Caller.Line
+	}
+
+	for k, v := range entry.Context {
+		data[gelfFieldName(k)] = gelfValue(v)
+	}
+	for k, v := range entry.FieldMap() {
+		data[gelfFieldName(k)] = gelfValue(v)
+	}
+
+	result, err := json.Marshal(data)
+	if err != nil {
+		return []byte(fmt.Sprintf(`{"version":"1.1","host":%q,"short_message":"marshal failed: %v"}`, g.Host, err))
+	}
+	return append(result, g.Delimiter)
+}
+
+// gelfFieldName prefixes additional field names, "_id" is reserved by GELF
+func gelfFieldName(key string) string {
+	if key == "id" {
+		return "_id_"
+	}
+	return "_" + key
+}
+
+// gelfValue keeps numbers and renders everything else as a string,
+// since GELF additional fields only allow those types
+func gelfValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, string:
+		return val
+	case error:
+		return val.Error()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HTMLLayout formats each entry as a table row with per-level colors.
+// Styles are inlined so rows stay readable in mail clients that strip
+// <style> blocks. Header and Footer return the surrounding document and
+// can be passed to an appender's WithHeader/WithFooter:
+//
+//	h := NewHTMLLayout()
+//	NewFileAppender("alerts.html").WithLayout(h).WithHeader(h.Header).WithFooter(h.Footer)
+type HTMLLayout struct {
+	Title        string
+	TimeFormat   string
+	Location     *time.Location // zone of the time column, nil uses SetTimeZone
+	LocationInfo bool
+	Colors       map[Level]string // row background per level
+}
+
+// NewHTMLLayout creates a new HTML layout
+func NewHTMLLayout() *HTMLLayout {
+	return &HTMLLayout{
+		Title:        "Log Messages",
+		TimeFormat:   "2006-01-02 15:04:05.000",
+		LocationInfo: true,
+		Colors: map[Level]string{
+			TRACE: "#f8f8f8",
+			DEBUG: "#f0f4f8",
+			INFO:  "#ffffff",
+			WARN:  "#fff3cd",
+			ERROR: "#f8d7da",
+			PANIC: "#f5a3a8",
+			FATAL: "#f5a3a8",
+		},
+	}
+}
+
+// WithTitle sets the document title
+func (h *HTMLLayout) WithTitle(title string) *HTMLLayout {
+	h.Title = title
+	return h
+}
+
+// WithTimeFormat sets the time format
+func (h *HTMLLayout) WithTimeFormat(format string) *HTMLLayout {
+	h.TimeFormat = format
+	return h
+}
+
+// WithLocation formats the time column in loc, e.g. time.UTC
+func (h *HTMLLayout) WithLocation(loc *time.Location) *HTMLLayout {
+	h.Location = loc
+	return h
+}
+
+// WithLocationInfo enables/disables the caller column
+func (h *HTMLLayout) WithLocationInfo(include bool) *HTMLLayout {
+	h.LocationInfo = include
+	return h
+}
+
+// WithLevelColor sets the row background color for a level
+func (h *HTMLLayout) WithLevelColor(level Level, color string) *HTMLLayout {
+	h.Colors[level] = color
+	return h
+}
+
+const htmlCell = `<td style="padding:4px 8px;border-bottom:1px solid #ddd;vertical-align:top">`
+
+// Header returns the document start and table header row
+func (h *HTMLLayout) Header() string {
+	var buf strings.Builder
+	title := html.EscapeString(h.Title)
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>")
+	buf.WriteString(title)
+	buf.WriteString("</title>\n</head>\n<body style=\"font-family:Arial,sans-serif;font-size:13px\">\n<h3>")
+	buf.WriteString(title)
+	buf.WriteString("</h3>\n<table style=\"border-collapse:collapse;width:100%\">\n<tr style=\"background:#333;color:#fff;text-align:left\">")
+	columns := []string{"Time", "Level", "Logger"}
+	if h.LocationInfo {
+		columns = append(columns, "File:Line")
+	}
+	columns = append(columns, "Message")
+	for _, c := range columns {
+		buf.WriteString(`<th style="padding:4px 8px">`)
+		buf.WriteString(c)
+		buf.WriteString("</th>")
+	}
+	buf.WriteString("</tr>\n")
+	return buf.String()
+}
+
+// Footer returns the table and document end
+func (h *HTMLLayout) Footer() string {
+	return "</table>\n</body>\n</html>\n"
+}
+
+// Format converts entry to a table row
+func (h *HTMLLayout) Format(entry *Entry) []byte {
+	var buf strings.Builder
+
+	buf.WriteString(`<tr style="background:`)
+	buf.WriteString(html.EscapeString(h.Colors[entry.Level]))
+	buf.WriteString(`">`)
+
+	h.cell(&buf, inZone(entry.Time, h.Location).Format(h.TimeFormat))
+	buf.WriteString(htmlCell)
+	if entry.Level >= ERROR {
+		buf.WriteString("<b>" + entry.Level.String() + "</b>")
+	} else {
+		buf.WriteString(entry.Level.String())
+	}
+	buf.WriteString("</td>")
+	h.cell(&buf, entry.Logger)
+	if h.LocationInfo {
+		location := ""
+		if entry.Caller.File != "" {
+			location = fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+		}
+		h.cell(&buf, location)
+	}
+
+	buf.WriteString(htmlCell)
+	if entry.Marker != "" {
+		buf.WriteString("[" + html.EscapeString(entry.Marker) + "] ")
+	}
+	buf.WriteString(htmlLines(entry.Message))
+	if entry.Error != nil {
+		buf.WriteString(`<pre style="margin:4px 0 0;color:#a00;white-space:pre-wrap">`)
+		buf.WriteString(html.EscapeString(entry.Error.Error()))
+		buf.WriteString("</pre>")
+	}
+	if fields := entry.FieldMap(); len(entry.Context)+len(fields) > 0 {
+		props := make(map[string]string, len(entry.Context)+len(fields))
+		for k, v := range entry.Context {
+			props[k] = fmt.Sprint(v)
+		}
+		for k, v := range fields {
+			props[k] = fmt.Sprint(v)
+		}
+		keys := make([]string, 0, len(props))
+		for k := range props {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf.WriteString(`<div style="color:#666;font-size:11px">`)
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(html.EscapeString(k) + "=" + html.EscapeString(props[k]))
+		}
+		buf.WriteString("</div>")
+	}
+	buf.WriteString("</td></tr>\n")
+	return []byte(buf.String())
+}
+
+// cell writes an escaped table cell
+func (h *HTMLLayout) cell(buf *strings.Builder, text string) {
+	buf.WriteString(htmlCell)
+	buf.WriteString(html.EscapeString(text))
+	buf.WriteString("</td>")
+}
+
+// htmlLines escapes text and keeps line breaks
+func htmlLines(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
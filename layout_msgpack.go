@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// MsgpackLayout encodes entries as MessagePack maps for compact binary
+// transport. Messages are self-delimiting, so no separator is written.
+//
+// Schema (keys in this order, optional ones omitted when empty):
+//
+//	time     int    Unix time in nanoseconds
+//	level    str    TRACE, DEBUG, INFO, WARN, ERROR, PANIC, FATAL
+//	logger   str    logger name
+//	message  str    formatted message
+//	marker   str    optional
+//	file     str    optional, caller file
+//	line     int    optional, caller line
+//	error    str    optional
+//	context  map    optional, MDC values
+//	fields   map    optional, structured fields
+type MsgpackLayout struct{}
+
+// NewMsgpackLayout creates a new MessagePack layout
+func NewMsgpackLayout() *MsgpackLayout {
+	return &MsgpackLayout{}
+}
+
+// Format converts entry to a MessagePack map
+func (m *MsgpackLayout) Format(entry *Entry) []byte {
+	fields := entry.FieldMap()
+	size := 4
+	if entry.Marker != "" {
+		size++
+	}
+	if entry.Caller.File != "" {
+		size += 2
+	}
+	if entry.Error != nil {
+		size++
+	}
+	if len(entry.Context) > 0 {
+		size++
+	}
+	if len(fields) > 0 {
+		size++
+	}
+
+	buf := make([]byte, 0, 256)
+	buf = msgpackMapHeader(buf, size)
+	buf = msgpackInt(msgpackString(buf, "time"), entry.Time.UnixNano())
+	buf = msgpackString(msgpackString(buf, "level"), entry.Level.String())
+	buf = msgpackString(msgpackString(buf, "logger"), entry.Logger)
+	buf = msgpackString(msgpackString(buf, "message"), entry.Message)
+	if entry.Marker != "" {
+		buf = msgpackString(msgpackString(buf, "marker"), entry.Marker)
+	}
+	if entry.Caller.File != "" {
+		buf = msgpackString(msgpackString(buf, "file"), entry.Caller.File)
+		buf = msgpackInt(msgpackString(buf, "line"), int64(entry.Caller.Line))
+	}
+	if entry.Error != nil {
+		buf = msgpackString(msgpackString(buf, "error"), entry.Error.Error())
+	}
+	if len(entry.Context) > 0 {
+		buf = msgpackValue(msgpackString(buf, "context"), entry.Context)
+	}
+	if len(fields) > 0 {
+		buf = msgpackValue(msgpackString(buf, "fields"), fields)
+	}
+	return buf
+}
+
+func msgpackMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, 0xde, byte(n>>8), byte(n))
+	}
+	return append(buf, 0xdf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func msgpackArrayHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, 0xdc, byte(n>>8), byte(n))
+	}
+	return append(buf, 0xdd, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func msgpackString(buf []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, 0xda, byte(n>>8), byte(n))
+	default:
+		buf = append(buf, 0xdb, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(buf, s...)
+}
+
+func msgpackBinary(buf []byte, b []byte) []byte {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, 0xc5, byte(n>>8), byte(n))
+	default:
+		buf = append(buf, 0xc6, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(buf, b...)
+}
+
+func msgpackInt(buf []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return msgpackUint(buf, uint64(v))
+	case v >= -32:
+		return append(buf, byte(v))
+	case v >= math.MinInt8:
+		return append(buf, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return append(buf, 0xd1, byte(v>>8), byte(v))
+	case v >= math.MinInt32:
+		return append(buf, 0xd2, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	return append(buf, 0xd3, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32),
+		byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func msgpackUint(buf []byte, v uint64) []byte {
+	switch {
+	case v < 128:
+		return append(buf, byte(v))
+	case v <= math.MaxUint8:
+		return append(buf, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return append(buf, 0xcd, byte(v>>8), byte(v))
+	case v <= math.MaxUint32:
+		return append(buf, 0xce, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	return append(buf, 0xcf, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32),
+		byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func msgpackFloat(buf []byte, f float64) []byte {
+	v := math.Float64bits(f)
+	return append(buf, 0xcb, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32),
+		byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// msgpackValue encodes common Go values, falling back to their string form
+func msgpackValue(buf []byte, v interface{}) []byte {
+	switch val := v.(type) {
+	case nil:
+		return append(buf, 0xc0)
+	case bool:
+		if val {
+			return append(buf, 0xc3)
+		}
+		return append(buf, 0xc2)
+	case int:
+		return msgpackInt(buf, int64(val))
+	case int8:
+		return msgpackInt(buf, int64(val))
+	case int16:
+		return msgpackInt(buf, int64(val))
+	case int32:
+		return msgpackInt(buf, int64(val))
+	case int64:
+		return msgpackInt(buf, val)
+	case uint:
+		return msgpackUint(buf, uint64(val))
+	case uint8:
+		return msgpackUint(buf, uint64(val))
+	case uint16:
+		return msgpackUint(buf, uint64(val))
+	case uint32:
+		return msgpackUint(buf, uint64(val))
+	case uint64:
+		return msgpackUint(buf, val)
+	case float32:
+		return msgpackFloat(buf, float64(val))
+	case float64:
+		return msgpackFloat(buf, val)
+	case string:
+		return msgpackString(buf, val)
+	case []byte:
+		return msgpackBinary(buf, val)
+	case time.Time:
+		return msgpackString(buf, val.Format(time.RFC3339Nano))
+	case time.Duration:
+		return msgpackInt(buf, int64(val))
+	case error:
+		return msgpackString(buf, val.Error())
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf = msgpackMapHeader(buf, len(keys))
+		for _, k := range keys {
+			buf = msgpackValue(msgpackString(buf, k), val[k])
+		}
+		return buf
+	case Fields:
+		return msgpackValue(buf, map[string]interface{}(val))
+	case []interface{}:
+		buf = msgpackArrayHeader(buf, len(val))
+		for _, item := range val {
+			buf = msgpackValue(buf, item)
+		}
+		return buf
+	case []string:
+		buf = msgpackArrayHeader(buf, len(val))
+		for _, item := range val {
+			buf = msgpackString(buf, item)
+		}
+		return buf
+	}
+	return msgpackString(buf, fmt.Sprint(v))
+}
@@ -0,0 +1,86 @@
+package logger
+
+import "bytes"
+
+// Multi-line modes for MultilineLayout
+const (
+	MultilineEscape = "escape" // replace embedded newlines with a literal \n
+	MultilineIndent = "indent" // start continuation lines with a prefix
+)
+
+// MultilineLayout keeps each entry on one logical record for line oriented
+// collectors. Newlines inside the formatted output (multi-line messages,
+// stack traces) are escaped or followed by an indent prefix, the trailing
+// newline is kept as is.
+type MultilineLayout struct {
+	inner  Layout
+	mode   string
+	prefix string
+}
+
+// NewMultilineLayout wraps a layout, escaping embedded newlines by default
+func NewMultilineLayout(inner Layout) *MultilineLayout {
+	return &MultilineLayout{
+		inner:  inner,
+		mode:   MultilineEscape,
+		prefix: "\t",
+	}
+}
+
+// EscapeNewlines writes embedded newlines as \n (and carriage returns as \r)
+func (m *MultilineLayout) EscapeNewlines() *MultilineLayout {
+	m.mode = MultilineEscape
+	return m
+}
+
+// IndentContinuation starts every continuation line with prefix
+func (m *MultilineLayout) IndentContinuation(prefix string) *MultilineLayout {
+	m.mode = MultilineIndent
+	m.prefix = prefix
+	return m
+}
+
+// Format converts entry using the inner layout
+func (m *MultilineLayout) Format(entry *Entry) []byte {
+	var buf bytes.Buffer
+	m.AppendFormat(&buf, entry)
+	return buf.Bytes()
+}
+
+// AppendFormat implements BufferedLayout
+func (m *MultilineLayout) AppendFormat(buf *bytes.Buffer, entry *Entry) {
+	formatted := formatEntry(m.inner, entry)
+	defer freeBuffer(formatted)
+
+	data := formatted.Bytes()
+	trailing := bytes.HasSuffix(data, []byte{'\n'})
+	if trailing {
+		data = data[:len(data)-1]
+	}
+
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "\r\n")
+		if i < 0 {
+			buf.Write(data)
+			break
+		}
+		buf.Write(data[:i])
+		if m.mode == MultilineIndent {
+			// Normalize \r\n and lone \r to \n
+			if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+				i++
+			}
+			buf.WriteByte('\n')
+			buf.WriteString(m.prefix)
+		} else if data[i] == '\r' {
+			buf.WriteString(`\r`)
+		} else {
+			buf.WriteString(`\n`)
+		}
+		data = data[i+1:]
+	}
+
+	if trailing {
+		buf.WriteByte('\n')
+	}
+}
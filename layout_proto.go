@@ -0,0 +1,269 @@
+package logger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ProtoSchemaVersion is the protobuf package entries are encoded with,
+// see proto/log_entry.proto
+const ProtoSchemaVersion = "eden.logger.v1"
+
+// ProtoLayout serializes entries as eden.logger.v1.LogEntry protobuf
+// messages. With LengthPrefixed (the default) each message is preceded by
+// its varint length, the standard delimited framing for streams and files.
+type ProtoLayout struct {
+	LengthPrefixed bool
+}
+
+// NewProtoLayout creates a length-prefixed protobuf layout
+func NewProtoLayout() *ProtoLayout {
+	return &ProtoLayout{LengthPrefixed: true}
+}
+
+// WithLengthPrefix enables/disables varint length framing
+func (p *ProtoLayout) WithLengthPrefix(prefixed bool) *ProtoLayout {
+	p.LengthPrefixed = prefixed
+	return p
+}
+
+// Format converts entry to a protobuf message
+func (p *ProtoLayout) Format(entry *Entry) []byte {
+	msg := NewProtoEntry(entry).Marshal()
+	if !p.LengthPrefixed {
+		return msg
+	}
+	buf := binary.AppendUvarint(make([]byte, 0, len(msg)+binary.MaxVarintLen32), uint64(len(msg)))
+	return append(buf, msg...)
+}
+
+// ProtoEntry is the Go form of the eden.logger.v1.LogEntry message
+type ProtoEntry struct {
+	TimeUnixNano int64
+	Level        Level
+	Logger       string
+	Message      string
+	Marker       string
+	File         string
+	Line         int32
+	Function     string
+	Error        string
+	Context      map[string]string
+	Fields       map[string]string
+}
+
+// NewProtoEntry converts an Entry, rendering context and field values as strings
+func NewProtoEntry(entry *Entry) *ProtoEntry {
+	pe := &ProtoEntry{
+		TimeUnixNano: entry.Time.UnixNano(),
+		Level:        entry.Level,
+		Logger:       entry.Logger,
+		Message:      entry.Message,
+		Marker:       entry.Marker,
+		File:         entry.Caller.File,
+		Line:         int32(entry.Caller.Line),
+		Function:     entry.Caller.Function,
+	}
+	if entry.Error != nil {
+		pe.Error = entry.Error.Error()
+	}
+	if len(entry.Context) > 0 {
+		pe.Context = make(map[string]string, len(entry.Context))
+		for k, v := range entry.Context {
+			pe.Context[k] = fmt.Sprint(v)
+		}
+	}
+	if fields := entry.FieldMap(); len(fields) > 0 {
+		pe.Fields = make(map[string]string, len(fields))
+		for k, v := range fields {
+			pe.Fields[k] = fmt.Sprint(v)
+		}
+	}
+	return pe
+}
+
+// protobuf wire types
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+// protoLevel maps a level to its wire value, PANIC was added after FATAL
+// in the schema and keeps a number of its own
+func protoLevel(level Level) uint64 {
+	switch level {
+	case PANIC:
+		return 6
+	case FATAL:
+		return 5
+	}
+	return uint64(level)
+}
+
+// levelFromProto is the inverse of protoLevel
+func levelFromProto(value uint64) Level {
+	switch value {
+	case 5:
+		return FATAL
+	case 6:
+		return PANIC
+	}
+	return Level(value)
+}
+
+// Marshal encodes the entry in protobuf wire format
+// Map entries are written in sorted key order so output is deterministic.
+func (pe *ProtoEntry) Marshal() []byte {
+	buf := make([]byte, 0, 128+len(pe.Message))
+	buf = protoVarint(buf, 1, uint64(pe.TimeUnixNano))
+	buf = protoVarint(buf, 2, protoLevel(pe.Level))
+	buf = protoString(buf, 3, pe.Logger)
+	buf = protoString(buf, 4, pe.Message)
+	buf = protoString(buf, 5, pe.Marker)
+	buf = protoString(buf, 6, pe.File)
+	buf = protoVarint(buf, 7, uint64(pe.Line))
+	buf = protoString(buf, 8, pe.Function)
+	buf = protoString(buf, 9, pe.Error)
+	buf = protoMap(buf, 10, pe.Context)
+	buf = protoMap(buf, 11, pe.Fields)
+	return buf
+}
+
+// UnmarshalProtoEntry decodes a LogEntry message (without length prefix)
+func UnmarshalProtoEntry(data []byte) (*ProtoEntry, error) {
+	pe := &ProtoEntry{}
+	for len(data) > 0 {
+		field, wire, value, raw, rest, err := protoNext(data)
+		if err != nil {
+			return nil, err
+		}
+		data = rest
+
+		switch field {
+		case 1:
+			pe.TimeUnixNano = int64(value)
+		case 2:
+			pe.Level = levelFromProto(value)
+		case 3:
+			pe.Logger = string(raw)
+		case 4:
+			pe.Message = string(raw)
+		case 5:
+			pe.Marker = string(raw)
+		case 6:
+			pe.File = string(raw)
+		case 7:
+			pe.Line = int32(value)
+		case 8:
+			pe.Function = string(raw)
+		case 9:
+			pe.Error = string(raw)
+		case 10, 11:
+			if wire != wireBytes {
+				return nil, errors.New("proto: invalid map entry")
+			}
+			k, v, err := protoMapEntry(raw)
+			if err != nil {
+				return nil, err
+			}
+			if field == 10 {
+				if pe.Context == nil {
+					pe.Context = make(map[string]string)
+				}
+				pe.Context[k] = v
+			} else {
+				if pe.Fields == nil {
+					pe.Fields = make(map[string]string)
+				}
+				pe.Fields[k] = v
+			}
+		}
+	}
+	return pe, nil
+}
+
+func protoTag(buf []byte, field int, wire int) []byte {
+	return binary.AppendUvarint(buf, uint64(field<<3|wire))
+}
+
+// protoVarint writes a varint field, omitting the proto3 default (0)
+func protoVarint(buf []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return buf
+	}
+	return binary.AppendUvarint(protoTag(buf, field, wireVarint), v)
+}
+
+// protoString writes a string field, omitting the proto3 default ("")
+func protoString(buf []byte, field int, s string) []byte {
+	if s == "" {
+		return buf
+	}
+	buf = binary.AppendUvarint(protoTag(buf, field, wireBytes), uint64(len(s)))
+	return append(buf, s...)
+}
+
+// protoMap writes a map<string, string> field as repeated entry messages
+func protoMap(buf []byte, field int, m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var entry []byte
+	for _, k := range keys {
+		entry = protoString(protoString(entry[:0], 1, k), 2, m[k])
+		buf = binary.AppendUvarint(protoTag(buf, field, wireBytes), uint64(len(entry)))
+		buf = append(buf, entry...)
+	}
+	return buf
+}
+
+// protoNext reads one field, returning its varint value or raw bytes
+func protoNext(data []byte) (field int, wire int, value uint64, raw []byte, rest []byte, err error) {
+	tag, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, 0, 0, nil, nil, errors.New("proto: invalid tag")
+	}
+	data = data[n:]
+	field, wire = int(tag>>3), int(tag&7)
+
+	switch wire {
+	case wireVarint:
+		value, n = binary.Uvarint(data)
+		if n <= 0 {
+			return 0, 0, 0, nil, nil, errors.New("proto: invalid varint")
+		}
+		return field, wire, value, nil, data[n:], nil
+	case wireBytes:
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return 0, 0, 0, nil, nil, errors.New("proto: invalid length")
+		}
+		data = data[n:]
+		return field, wire, 0, data[:size], data[size:], nil
+	}
+	return 0, 0, 0, nil, nil, fmt.Errorf("proto: unsupported wire type %d", wire)
+}
+
+// protoMapEntry decodes a map entry message
+func protoMapEntry(data []byte) (string, string, error) {
+	var k, v string
+	for len(data) > 0 {
+		field, _, _, raw, rest, err := protoNext(data)
+		if err != nil {
+			return "", "", err
+		}
+		data = rest
+		switch field {
+		case 1:
+			k = string(raw)
+		case 2:
+			v = string(raw)
+		}
+	}
+	return k, v, nil
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// StackdriverLayout formats logs as the structured JSON that Google Cloud
+// Logging agents (GKE, Cloud Run, Cloud Functions) ingest natively.
+// Severity, time, source location and trace are written under their special
+// keys, everything else (fields, MDC context, logger, marker) ends up in the
+// entry's jsonPayload.
+type StackdriverLayout struct {
+	ProjectID string // used to expand trace IDs to projects/<id>/traces/<trace>
+	TraceKey  string // field/context key holding the trace ID
+	SpanKey   string // field/context key holding the span ID
+}
+
+// NewStackdriverLayout creates a Cloud Logging layout reading trace_id/span_id
+func NewStackdriverLayout() *StackdriverLayout {
+	return &StackdriverLayout{
+		TraceKey: "trace_id",
+		SpanKey:  "span_id",
+	}
+}
+
+// WithProjectID sets the GCP project used for trace resource names
+func (s *StackdriverLayout) WithProjectID(projectID string) *StackdriverLayout {
+	s.ProjectID = projectID
+	return s
+}
+
+// WithTraceKeys sets the field/context keys holding trace and span IDs
+func (s *StackdriverLayout) WithTraceKeys(traceKey, spanKey string) *StackdriverLayout {
+	s.TraceKey = traceKey
+	s.SpanKey = spanKey
+	return s
+}
+
+// stackdriverSeverity maps a level to a Cloud Logging LogSeverity
+func stackdriverSeverity(level Level) string {
+	switch level {
+	case TRACE, DEBUG:
+		return "DEBUG"
+	case INFO:
+		return "INFO"
+	case WARN:
+		return "WARNING"
+	case ERROR:
+		return "ERROR"
+	case PANIC, FATAL:
+		return "CRITICAL"
+	}
+	return "DEFAULT"
+}
+
+// Format converts entry to Cloud Logging JSON
+func (s *StackdriverLayout) Format(entry *Entry) []byte {
+	data := make(map[string]interface{}, len(entry.Context)+len(entry.Fields)+8)
+	for k, v := range entry.Context {
+		data[k] = v
+	}
+	for k, v := range entry.FieldMap() {
+		data[k] = v
+	}
+
+	trace, span := s.lookup(entry, s.TraceKey), s.lookup(entry, s.SpanKey)
+	if trace != "" {
+		delete(data, s.TraceKey)
+		if s.ProjectID != "" {
+			trace = "projects/" + s.ProjectID + "/traces/" + trace
+		}
+		data["logging.googleapis.com/trace"] = trace
+	}
+	if span != "" {
+		delete(data, s.SpanKey)
+		data["logging.googleapis.com/spanId"] = span
+	}
+
+	data["severity"] = stackdriverSeverity(entry.Level)
+	data["time"] = inZone(entry.Time, nil).Format("2006-01-02T15:04:05.000000000Z07:00")
+	data["message"] = entry.Message
+	if entry.Logger != "" {
+		data["logger"] = entry.Logger
+	}
+	if entry.Marker != "" {
+		data["marker"] = entry.Marker
+	}
+	if entry.Error != nil {
+		// Error Reporting picks up errors from the message, keep both
+		data["error"] = entry.Error.Error()
+		data["message"] = entry.Message + "\n" + entry.Error.Error()
+	}
+	if entry.Caller.File != "" {
+		data["logging.googleapis.com/sourceLocation"] = map[string]string{
+			"file":     entry.Caller.File,
+			"line":     strconv.Itoa(entry.Caller.Line),
+			"function": entry.Caller.Function,
+		}
+	}
+
+	result, err := json.Marshal(data)
+	if err != nil {
+		return []byte(fmt.Sprintf(`{"severity":"ERROR","message":"marshal failed: %v"}`+"\n", err))
+	}
+	return append(result, '\n')
+}
+
+// lookup returns a trace value from fields, falling back to MDC context
+func (s *StackdriverLayout) lookup(entry *Entry, key string) string {
+	if key == "" {
+		return ""
+	}
+	if v, ok := entry.FieldValue(key); ok {
+		return fmt.Sprint(v)
+	}
+	if v, ok := entry.Context[key]; ok {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SyslogLayout formats logs as RFC 5424 syslog messages:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID k="v" ...] MSG
+//
+// MSGID is the marker, and Fields become the parameters of a single
+// structured data element. With OctetCounting each frame is prefixed with
+// its length (RFC 6587) for TCP transport, otherwise a newline is appended.
+type SyslogLayout struct {
+	Facility      int // 1 = user (default), 16-23 = local0-local7
+	Hostname      string
+	AppName       string
+	ProcID        string
+	SDID          string // structured data ID, e.g. "fields@32473"
+	OctetCounting bool
+}
+
+// NewSyslogLayout creates a syslog layout using the local hostname, binary name and pid
+func NewSyslogLayout() *SyslogLayout {
+	host, _ := os.Hostname()
+	return &SyslogLayout{
+		Facility: 1,
+		Hostname: host,
+		AppName:  filepath.Base(os.Args[0]),
+		ProcID:   strconv.Itoa(os.Getpid()),
+		SDID:     "fields@32473",
+	}
+}
+
+// WithFacility sets the syslog facility (0-23)
+func (s *SyslogLayout) WithFacility(facility int) *SyslogLayout {
+	s.Facility = facility
+	return s
+}
+
+// WithHostname overrides the reported hostname
+func (s *SyslogLayout) WithHostname(host string) *SyslogLayout {
+	s.Hostname = host
+	return s
+}
+
+// WithAppName overrides the application name
+func (s *SyslogLayout) WithAppName(name string) *SyslogLayout {
+	s.AppName = name
+	return s
+}
+
+// WithSDID sets the structured data ID fields are written under
+func (s *SyslogLayout) WithSDID(id string) *SyslogLayout {
+	s.SDID = id
+	return s
+}
+
+// WithOctetCounting enables/disables RFC 6587 length framing
+func (s *SyslogLayout) WithOctetCounting(enabled bool) *SyslogLayout {
+	s.OctetCounting = enabled
+	return s
+}
+
+var syslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// Format converts entry to a syslog frame
+func (s *SyslogLayout) Format(entry *Entry) []byte {
+	var buf strings.Builder
+
+	pri := (s.Facility&0x1f)*8 + syslogSeverity(entry.Level)
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s %s ",
+		pri,
+		inZone(entry.Time, nil).Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeader(s.Hostname, 255),
+		syslogHeader(s.AppName, 48),
+		syslogHeader(s.ProcID, 128),
+		syslogHeader(entry.Marker, 32),
+	)
+
+	if fields := entry.FieldMap(); len(fields) > 0 && s.SDID != "" {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('[')
+		buf.WriteString(syslogName(s.SDID))
+		for _, k := range keys {
+			buf.WriteByte(' ')
+			buf.WriteString(syslogName(k))
+			buf.WriteString(`="`)
+			buf.WriteString(syslogParamEscaper.Replace(fmt.Sprint(fields[k])))
+			buf.WriteByte('"')
+		}
+		buf.WriteByte(']')
+	} else {
+		buf.WriteByte('-')
+	}
+
+	if entry.Message != "" || entry.Error != nil {
+		buf.WriteByte(' ')
+		buf.WriteString(entry.Message)
+		if entry.Error != nil {
+			if entry.Message != "" {
+				buf.WriteString(": ")
+			}
+			buf.WriteString(entry.Error.Error())
+		}
+	}
+
+	if s.OctetCounting {
+		return []byte(strconv.Itoa(buf.Len()) + " " + buf.String())
+	}
+	buf.WriteByte('\n')
+	return []byte(buf.String())
+}
+
+// syslogHeader returns a header field limited to printable ASCII, or the nil value "-"
+func syslogHeader(v string, max int) string {
+	if v == "" {
+		return "-"
+	}
+	v = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, v)
+	if len(v) > max {
+		v = v[:max]
+	}
+	return v
+}
+
+// syslogName sanitizes an SD-ID or parameter name (max 32 chars, no '=', ' ', ']' or '"')
+func syslogName(v string) string {
+	v = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, v)
+	if len(v) > 32 {
+		v = v[:32]
+	}
+	return v
+}
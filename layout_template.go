@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TemplateLayout renders entries through a text/template. The template is
+// executed with the *Entry as dot, so .Time, .Level, .Logger, .Message,
+// .Marker, .Caller, .Error, .Context and .Fields are all available
+// (.FieldMap also includes typed fields).
+//
+// Helper functions:
+//
+//	pad N s    - right-pad s to N characters
+//	lpad N s   - left-pad s to N characters
+//	upper s    - upper case
+//	lower s    - lower case
+//	json v     - JSON encoding of v
+//
+// Example: `{{.Time.Format "15:04:05"}} {{pad 5 .Level}} {{.Message}}{{"\n"}}`
+type TemplateLayout struct {
+	tmpl *template.Template
+}
+
+// templateFuncs are the helpers available to every TemplateLayout
+var templateFuncs = template.FuncMap{
+	"pad": func(width int, v interface{}) string {
+		return fmt.Sprintf("%-*s", width, fmt.Sprint(v))
+	},
+	"lpad": func(width int, v interface{}) string {
+		return fmt.Sprintf("%*s", width, fmt.Sprint(v))
+	},
+	"upper": func(v interface{}) string {
+		return strings.ToUpper(fmt.Sprint(v))
+	},
+	"lower": func(v interface{}) string {
+		return strings.ToLower(fmt.Sprint(v))
+	},
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// NewTemplateLayout parses a template layout, extra funcs are added to
+// (or override) the built-in helpers
+func NewTemplateLayout(text string, funcs ...template.FuncMap) (*TemplateLayout, error) {
+	tmpl := template.New("layout").Funcs(templateFuncs)
+	for _, f := range funcs {
+		tmpl = tmpl.Funcs(f)
+	}
+	tmpl, err := tmpl.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateLayout{tmpl: tmpl}, nil
+}
+
+// MustTemplateLayout creates a template layout, panics on invalid template
+func MustTemplateLayout(text string, funcs ...template.FuncMap) *TemplateLayout {
+	t, err := NewTemplateLayout(text, funcs...)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Format renders the template for an entry
+func (t *TemplateLayout) Format(entry *Entry) []byte {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, entry); err != nil {
+		return []byte(fmt.Sprintf("template failed: %v\n", err))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,56 @@
+package logger
+
+import "testing"
+
+// TestPatternLayoutModifiers covers log4j-style padding and truncation
+func TestPatternLayoutModifiers(t *testing.T) {
+	entry := &Entry{
+		Level:   INFO,
+		Logger:  "app.db",
+		Message: "hello world",
+	}
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"%p", "INFO"},
+		{"[%-5p]", "[INFO ]"},
+		{"[%5p]", "[ INFO]"},
+		{"[%10c]", "[    app.db]"},
+		{"[%-10c]", "[app.db    ]"},
+		{"%.5m", "world"},
+		{"%.-5m", "hello"},
+		{"[%8.5m]", "[   world]"},
+		{"[%-8.-5m]", "[hello   ]"},
+		{"%.20m", "hello world"},
+		{"[%3p]", "[INFO]"},
+		{"100%% %m", "100% hello world"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := string(NewPatternLayout(tt.pattern).Format(entry)); got != tt.want {
+				t.Errorf("Format(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPatternLayoutModifiersMultibyte pads and truncates by rune, not byte
+func TestPatternLayoutModifiersMultibyte(t *testing.T) {
+	entry := &Entry{Level: INFO, Message: "日志消息"}
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"%.2m", "消息"},
+		{"%.-2m", "日志"},
+		{"[%6m]", "[  日志消息]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := string(NewPatternLayout(tt.pattern).Format(entry)); got != tt.want {
+				t.Errorf("Format(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
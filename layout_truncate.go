@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+// TruncateLayout caps the message length so an accidental dump of a huge
+// payload can't blow out files or downstream ingestion limits. Longer
+// messages are cut at a rune boundary and end with
+// "…[truncated 12034 bytes]".
+type TruncateLayout struct {
+	inner Layout
+	max   int
+}
+
+// NewTruncateLayout wraps a layout, keeping at most maxBytes of the message
+func NewTruncateLayout(inner Layout, maxBytes int) *TruncateLayout {
+	return &TruncateLayout{inner: inner, max: maxBytes}
+}
+
+// Format converts entry using the inner layout
+func (t *TruncateLayout) Format(entry *Entry) []byte {
+	var buf bytes.Buffer
+	t.AppendFormat(&buf, entry)
+	return buf.Bytes()
+}
+
+// AppendFormat implements BufferedLayout
+func (t *TruncateLayout) AppendFormat(buf *bytes.Buffer, entry *Entry) {
+	if t.max > 0 && len(entry.Message) > t.max {
+		// the entry is shared with other appenders, format a copy
+		e := *entry
+		e.Message = truncateMessage(entry.Message, t.max)
+		entry = &e
+	}
+	formatted := formatEntry(t.inner, entry)
+	buf.Write(formatted.Bytes())
+	freeBuffer(formatted)
+}
+
+// truncateMessage cuts msg to at most max bytes without splitting a rune
+func truncateMessage(msg string, max int) string {
+	n := max
+	for n > 0 && !utf8.RuneStart(msg[n]) {
+		n--
+	}
+	return msg[:n] + "…[truncated " + strconv.Itoa(len(msg)-n) + " bytes]"
+}
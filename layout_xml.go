@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// XMLLayout formats logs as log4j XMLLayout events, readable by Chainsaw
+// and other legacy log viewers:
+//
+//	<log4j:event logger="app" timestamp="1714550400000" level="INFO" thread="7">
+//	<log4j:message><![CDATA[hello]]></log4j:message>
+//	<log4j:locationInfo class="" method="main.main" file="main.go" line="12"/>
+//	<log4j:properties><log4j:data name="k" value="v"/></log4j:properties>
+//	</log4j:event>
+type XMLLayout struct {
+	LocationInfo bool // emit log4j:locationInfo when caller info is available
+	Properties   bool // emit marker, MDC context and fields as log4j:data
+}
+
+// NewXMLLayout creates a new XML layout
+func NewXMLLayout() *XMLLayout {
+	return &XMLLayout{
+		LocationInfo: true,
+		Properties:   true,
+	}
+}
+
+// WithLocationInfo enables/disables location info
+func (x *XMLLayout) WithLocationInfo(include bool) *XMLLayout {
+	x.LocationInfo = include
+	return x
+}
+
+// WithProperties enables/disables properties
+func (x *XMLLayout) WithProperties(include bool) *XMLLayout {
+	x.Properties = include
+	return x
+}
+
+// Format converts entry to a log4j:event element
+func (x *XMLLayout) Format(entry *Entry) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<log4j:event logger="`)
+	xmlAttr(&buf, entry.Logger)
+	buf.WriteString(`" timestamp="`)
+	buf.WriteString(strconv.FormatInt(entry.Time.UnixMilli(), 10))
+	buf.WriteString(`" level="`)
+	buf.WriteString(entry.Level.String())
+	// Goroutines stand in for threads, empty unless SetGoroutineID is on
+	buf.WriteString(`" thread="`)
+	if entry.Goroutine != 0 {
+		buf.WriteString(strconv.FormatUint(entry.Goroutine, 10))
+	}
+	buf.WriteString("\">\r\n")
+
+	buf.WriteString("<log4j:message>")
+	xmlCDATA(&buf, entry.Message)
+	buf.WriteString("</log4j:message>\r\n")
+
+	if entry.Error != nil {
+		buf.WriteString("<log4j:throwable>")
+		xmlCDATA(&buf, entry.Error.Error())
+		buf.WriteString("</log4j:throwable>\r\n")
+	}
+
+	if x.LocationInfo && entry.Caller.File != "" {
+		class, method := splitFunction(entry.Caller.Function)
+		buf.WriteString(`<log4j:locationInfo class="`)
+		xmlAttr(&buf, class)
+		buf.WriteString(`" method="`)
+		xmlAttr(&buf, method)
+		buf.WriteString(`" file="`)
+		xmlAttr(&buf, entry.Caller.File)
+		buf.WriteString(`" line="`)
+		buf.WriteString(strconv.Itoa(entry.Caller.Line))
+		buf.WriteString("\"/>\r\n")
+	}
+
+	if x.Properties {
+		props := make(map[string]string, len(entry.Context)+len(entry.Fields)+1)
+		for k, v := range entry.Context {
+			props[k] = fmt.Sprint(v)
+		}
+		for k, v := range entry.FieldMap() {
+			props[k] = fmt.Sprint(v)
+		}
+		if entry.Marker != "" {
+			props["marker"] = entry.Marker
+		}
+
+		if len(props) > 0 {
+			keys := make([]string, 0, len(props))
+			for k := range props {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			buf.WriteString("<log4j:properties>\r\n")
+			for _, k := range keys {
+				buf.WriteString(`<log4j:data name="`)
+				xmlAttr(&buf, k)
+				buf.WriteString(`" value="`)
+				xmlAttr(&buf, props[k])
+				buf.WriteString("\"/>\r\n")
+			}
+			buf.WriteString("</log4j:properties>\r\n")
+		}
+	}
+
+	buf.WriteString("</log4j:event>\r\n\r\n")
+	return buf.Bytes()
+}
+
+// splitFunction splits "pkg/path.Type.Method" into class and method parts
+func splitFunction(fn string) (string, string) {
+	slash := strings.LastIndexByte(fn, '/')
+	if dot := strings.LastIndexByte(fn, '.'); dot > slash {
+		return fn[:dot], fn[dot+1:]
+	}
+	return "", fn
+}
+
+// xmlAttr writes s escaped for use in an attribute value
+func xmlAttr(buf *bytes.Buffer, s string) {
+	xml.EscapeText(buf, []byte(s))
+}
+
+// xmlCDATA writes s as a CDATA section, splitting any "]]>" it contains
+func xmlCDATA(buf *bytes.Buffer, s string) {
+	buf.WriteString("<![CDATA[")
+	buf.WriteString(strings.ReplaceAll(s, "]]>", "]]]]><![CDATA[>"))
+	buf.WriteString("]]>")
+}
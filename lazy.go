@@ -0,0 +1,77 @@
+package logger
+
+import "fmt"
+
+// Lazy arguments
+//
+// A func() string argument is called only when the entry will be written,
+// so expensive debug output costs nothing while the level is disabled:
+//
+//	log.Debug("state: %s", func() string { return dump(state) })
+//
+// fmt.Stringer arguments get the same treatment, they are formatted only
+// after the same checks. The check covers the logger level and appender
+// threshold filters; other filters still see the formatted message.
+
+// LogFunc logs the result of fn at level, calling fn only when the entry will be written
+func (l *Logger) LogFunc(level Level, fn func() string) {
+	l.log(level, "", "%s", fn)
+}
+
+// hasLazyArgs reports whether args contain values that are cheaper to skip than to format
+func hasLazyArgs(args []interface{}) bool {
+	for _, arg := range args {
+		switch arg.(type) {
+		case func() string, fmt.Stringer:
+			return true
+		}
+	}
+	return false
+}
+
+// resolveLazyArgs returns args with every func() string replaced by its
+// result, copying rather than modifying the caller's slice
+func resolveLazyArgs(args []interface{}) []interface{} {
+	var resolved []interface{}
+	for i, arg := range args {
+		if fn, ok := arg.(func() string); ok {
+			if resolved == nil {
+				resolved = make([]interface{}, len(args))
+				copy(resolved, args)
+			}
+			resolved[i] = fn()
+		}
+	}
+	if resolved == nil {
+		return args
+	}
+	return resolved
+}
+
+// mayAccept reports whether any appender reachable from l could take an
+// entry at level. It only consults side-effect free threshold filters,
+// so it never consumes burst tokens or sampling counters.
+func (l *Logger) mayAccept(level Level) bool {
+	for lg := l; lg != nil; lg = lg.parentLogger() {
+		for _, a := range lg.loadAppenders() {
+			if appenderMayAccept(a, level) {
+				return true
+			}
+		}
+		if !lg.settings().additive {
+			break
+		}
+	}
+	return false
+}
+
+// appenderMayAccept checks an appender's threshold, unwrapping async appenders
+func appenderMayAccept(a Appender, level Level) bool {
+	switch v := a.(type) {
+	case *AsyncAppender:
+		return appenderMayAccept(v.delegate, level)
+	case interface{ levelAccepts(Level) bool }:
+		return v.levelAccepts(level)
+	}
+	return true
+}
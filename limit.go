@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LimitedLogger logs through a rate limit shared by every use of the same
+// call site, see Logger.Every and Logger.Once
+type LimitedLogger struct {
+	logger  *Logger
+	limiter *callSiteLimiter
+}
+
+type callSiteLimiter struct {
+	every time.Duration // <= 0 logs once
+	next  atomic.Int64  // unix nanos when the next entry may be logged
+}
+
+type callSiteKey struct {
+	pc    uintptr
+	every time.Duration
+}
+
+// callSiteLimiters is keyed by call site, which keeps it bounded
+var callSiteLimiters sync.Map
+
+// Every returns a logger writing at most one entry per d from the calling
+// line, e.g. in a retry loop: log.Every(time.Minute).Warn("retrying: %v", err)
+func (l *Logger) Every(d time.Duration) *LimitedLogger {
+	if d <= 0 {
+		d = time.Nanosecond
+	}
+	return &LimitedLogger{logger: l, limiter: limiterFor(callSite(), d)}
+}
+
+// Once returns a logger writing only the first entry from the calling line
+func (l *Logger) Once() *LimitedLogger {
+	return &LimitedLogger{logger: l, limiter: limiterFor(callSite(), 0)}
+}
+
+func limiterFor(pc uintptr, every time.Duration) *callSiteLimiter {
+	key := callSiteKey{pc: pc, every: every}
+	if v, ok := callSiteLimiters.Load(key); ok {
+		return v.(*callSiteLimiter)
+	}
+	v, _ := callSiteLimiters.LoadOrStore(key, &callSiteLimiter{every: every})
+	return v.(*callSiteLimiter)
+}
+
+// callSite returns the program counter of the first caller outside this package
+func callSite() uintptr {
+	var pcs [16]uintptr
+	n := runtime.Callers(2, pcs[:])
+	for _, pc := range pcs[:n] {
+		if frames := framesForPC(pc); !frames[len(frames)-1].internal {
+			return pc
+		}
+	}
+	return 0
+}
+
+// allow takes the slot for an entry, false while the limit holds
+func (c *callSiteLimiter) allow() bool {
+	now := time.Now().UnixNano()
+	next := c.next.Load()
+	if next < 0 || now < next {
+		return false
+	}
+	if c.every <= 0 {
+		return c.next.CompareAndSwap(next, -1)
+	}
+	return c.next.CompareAndSwap(next, now+int64(c.every))
+}
+
+// enabled checks the level before the limit, so disabled levels don't use it up
+func (m *LimitedLogger) enabled(level Level) bool {
+	return m.logger.IsEnabled(level) && m.limiter.allow()
+}
+
+func (m *LimitedLogger) Info(format string, args ...interface{}) {
+	if m.enabled(INFO) {
+		m.logger.log(INFO, "", format, args...)
+	}
+}
+
+func (m *LimitedLogger) Warn(format string, args ...interface{}) {
+	if m.enabled(WARN) {
+		m.logger.log(WARN, "", format, args...)
+	}
+}
+
+func (m *LimitedLogger) Error(format string, args ...interface{}) {
+	if m.enabled(ERROR) {
+		m.logger.log(ERROR, "", format, args...)
+	}
+}
+
+func (m *LimitedLogger) Infow(msg string, keysAndValues ...interface{}) {
+	if m.enabled(INFO) {
+		m.logger.logw(INFO, "", msg, keysAndValues)
+	}
+}
+
+func (m *LimitedLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	if m.enabled(WARN) {
+		m.logger.logw(WARN, "", msg, keysAndValues)
+	}
+}
+
+func (m *LimitedLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	if m.enabled(ERROR) {
+		m.logger.logw(ERROR, "", msg, keysAndValues)
+	}
+}
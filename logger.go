@@ -2,9 +2,14 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"runtime"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,6 +22,7 @@ const (
 	INFO
 	WARN
 	ERROR
+	PANIC
 	FATAL
 	OFF
 )
@@ -27,6 +33,7 @@ var levelNames = map[Level]string{
 	INFO:  "INFO",
 	WARN:  "WARN",
 	ERROR: "ERROR",
+	PANIC: "PANIC",
 	FATAL: "FATAL",
 	OFF:   "OFF",
 }
@@ -37,6 +44,7 @@ var levelValues = map[string]Level{
 	"INFO":  INFO,
 	"WARN":  WARN,
 	"ERROR": ERROR,
+	"PANIC": PANIC,
 	"FATAL": FATAL,
 	"OFF":   OFF,
 	"trace": TRACE,
@@ -44,6 +52,7 @@ var levelValues = map[string]Level{
 	"info":  INFO,
 	"warn":  WARN,
 	"error": ERROR,
+	"panic": PANIC,
 	"fatal": FATAL,
 }
 
@@ -64,15 +73,62 @@ func ParseLevel(s string) Level {
 
 // Entry represents a single log event
 type Entry struct {
-	Time    time.Time
-	Level   Level
-	Message string
-	Logger  string
-	Marker  string
-	Context map[string]interface{}
-	Caller  CallerInfo
-	Error   error
-	Fields  map[string]interface{}
+	Time      time.Time
+	Level     Level
+	Message   string
+	Template  string // format string the message was built from
+	Logger    string
+	Marker    string
+	Code      string // stable event code, see WithCode
+	Seq       uint64 // sequence number, 0 unless enabled with SetSequence
+	Goroutine uint64 // ID of the logging goroutine, 0 unless enabled with SetGoroutineID
+	Context   map[string]interface{}
+	Caller    CallerInfo
+	Error     error
+	Fields    map[string]interface{}
+	Typed     []Field   // typed fields from Log, read them through FieldMap/FieldValue
+	Stack     []uintptr // goroutine stack, captured at or above the logger's stack trace level
+
+	diag *DiagnosticBuffer // buffer of the context the entry was logged with
+
+	// pooling, see newEntry
+	refs    int32
+	context map[string]interface{}
+	fields  map[string]interface{}
+}
+
+// HasFields reports whether the entry carries any fields
+func (e *Entry) HasFields() bool {
+	return len(e.Fields) > 0 || len(e.Typed) > 0
+}
+
+// FieldMap returns Fields merged with the typed fields. Without typed
+// fields this is the Fields map itself and must not be modified.
+func (e *Entry) FieldMap() map[string]interface{} {
+	if len(e.Typed) == 0 {
+		return e.Fields
+	}
+	merged := make(map[string]interface{}, len(e.Fields)+len(e.Typed))
+	for k, v := range e.Fields {
+		merged[k] = v
+	}
+	for _, f := range e.Typed {
+		if f.Key != "" {
+			merged[f.Key] = f.Value()
+		}
+	}
+	return merged
+}
+
+// FieldValue returns a field by key, typed fields take precedence
+func (e *Entry) FieldValue(key string) (interface{}, bool) {
+	for i := len(e.Typed) - 1; i >= 0; i-- {
+		if e.Typed[i].Key == key {
+			return e.Typed[i].Value(), true
+		}
+	}
+	v, ok := e.Fields[key]
+	return v, ok
 }
 
 // CallerInfo holds source code location
@@ -117,6 +173,15 @@ func (m *MDC) Clear() {
 	m.data = make(map[string]interface{})
 }
 
+// copyTo adds the MDC values to dst
+func (m *MDC) copyTo(dst map[string]interface{}) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for k, v := range m.data {
+		dst[k] = v
+	}
+}
+
 func (m *MDC) Clone() map[string]interface{} {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -127,53 +192,357 @@ func (m *MDC) Clone() map[string]interface{} {
 	return clone
 }
 
+// Interface is the logging API shared by *Logger, *MarkerLogger,
+// *FieldLogger and *ContextLogger, so libraries can accept it and tests
+// can mock it
+type Interface interface {
+	Trace(format string, args ...interface{})
+	Debug(format string, args ...interface{})
+	Info(format string, args ...interface{})
+	Warn(format string, args ...interface{})
+	Error(format string, args ...interface{})
+	Fatal(format string, args ...interface{})
+	WithFields(fields map[string]interface{}) *FieldLogger
+	WithMarker(marker string) *MarkerLogger
+	WithCtx(ctx context.Context) *ContextLogger
+}
+
+var (
+	_ Interface = (*Logger)(nil)
+	_ Interface = (*MarkerLogger)(nil)
+	_ Interface = (*FieldLogger)(nil)
+	_ Interface = (*ContextLogger)(nil)
+)
+
 // Logger is the main logging interface
 type Logger struct {
-	name            string
-	level           Level
+	name      string
+	level     atomic.Int32                 // Level, or levelInherit
+	appenders atomic.Pointer[[]Appender]   // copied on write, read without locking
+	config    atomic.Pointer[loggerConfig] // copied on write, read without locking
+	mdc       *MDC
+	mu        sync.RWMutex // serializes updates of appenders and config, guards exitFunc
+
+	// hierarchy, see GetLogger
+	parent *Logger // nil for top-level registry loggers, whose parent is the root
+	named  bool    // created by the registry, Named or Clone
+
+	exitFunc func(code int) // called by Fatal, see SetExitFunc
+	exitSet  bool           // false uses the parent's exit func, or os.Exit
+
+	skip       atomic.Int32 // caller frames to skip, see WithCallerSkip
+	shutdown   atomic.Bool  // set by Shutdown, entries are no longer accepted
+	sampler    atomic.Pointer[Sampler]
+	sequenced  atomic.Bool   // number accepted entries, see SetSequence
+	sequence   atomic.Uint64 // last sequence number
+	goroutines atomic.Bool   // capture Entry.Goroutine, see SetGoroutineID
+
+	diagnostics atomic.Pointer[DiagnosticBuffer] // see SetDiagnosticBuffer
+}
+
+// loggerConfig holds the settings consulted for every entry. It is never
+// modified once published, setters store an updated copy.
+type loggerConfig struct {
 	includeLocation bool
-	appenders       []Appender
-	mdc             *MDC
-	mu              sync.RWMutex
+	locationLevel   Level // capture the caller at or above this level when includeLocation
+	additive        bool  // also write to the parent's appenders
+
+	stackLevel Level // capture Entry.Stack at or above this level, OFF disables
+	stackSet   bool  // false inherits the parent's stack trace level
+
+	filters    []Filter
+	hooks      []Hook
+	extractors []ContextExtractor // see AddContextExtractor
 }
 
+// levelInherit is stored as the level of registry loggers using their
+// parent's level
+const levelInherit = -1
+
 // NewLogger creates a new logger instance
 func NewLogger(name string) *Logger {
-	return &Logger{
-		name:            name,
-		level:           INFO,
-		includeLocation: false,
-		appenders:       make([]Appender, 0),
-		mdc:             NewMDC(),
+	l := &Logger{
+		name: name,
+		mdc:  NewMDC(),
 	}
+	l.level.Store(int32(INFO))
+	l.appenders.Store(&[]Appender{})
+	l.config.Store(&loggerConfig{additive: true, stackLevel: OFF, stackSet: true})
+	return l
 }
 
-// SetLevel sets the minimum log level
-func (l *Logger) SetLevel(level Level) {
+// settings returns the current settings, they must not be modified
+func (l *Logger) settings() *loggerConfig {
+	return l.config.Load()
+}
+
+// configure applies update to a copy of the settings and publishes it,
+// slices must be copied rather than appended to in place (see slices.Clip)
+func (l *Logger) configure(update func(c *loggerConfig)) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.level = level
+	c := *l.config.Load()
+	update(&c)
+	l.config.Store(&c)
+}
+
+// SetLevel sets the minimum log level
+func (l *Logger) SetLevel(level Level) {
+	l.level.Store(int32(level))
 }
 
 // SetIncludeLocation sets whether to include caller location
 func (l *Logger) SetIncludeLocation(include bool) {
+	l.configure(func(c *loggerConfig) {
+		c.includeLocation = include
+	})
+}
+
+// SetLocationLevel includes the caller location only for entries at or
+// above level, e.g. WARN, since capturing it dominates the cost of high
+// volume logging. OFF disables it like SetIncludeLocation(false).
+func (l *Logger) SetLocationLevel(level Level) {
+	l.configure(func(c *loggerConfig) {
+		c.includeLocation = level != OFF
+		c.locationLevel = level
+	})
+}
+
+// ClearLevel makes a registry logger inherit its parent's level again,
+// loggers outside the hierarchy keep their level
+func (l *Logger) ClearLevel() {
+	if l.named {
+		l.level.Store(levelInherit)
+	}
+}
+
+// SetStackTraceLevel captures the goroutine stack for entries at or above
+// level, e.g. ERROR, rendered by %ex and the JSON "stack_trace" key. OFF
+// (the default) disables capture.
+func (l *Logger) SetStackTraceLevel(level Level) {
+	l.configure(func(c *loggerConfig) {
+		c.stackLevel = level
+		c.stackSet = true
+	})
+}
+
+// stackEnabled reports whether entries at level carry a stack trace
+func (l *Logger) stackEnabled(level Level) bool {
+	c := l.settings()
+	if !c.stackSet {
+		if p := l.parentLogger(); p != nil {
+			return p.stackEnabled(level)
+		}
+	}
+	return level >= c.stackLevel && c.stackLevel != OFF
+}
+
+// SetExitFunc sets the function Fatal calls after closing the appenders,
+// os.Exit by default. Tests can capture the exit code, nil restores the
+// old behavior of logging and returning without closing anything.
+func (l *Logger) SetExitFunc(fn func(code int)) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.includeLocation = include
+	l.exitFunc = fn
+	l.exitSet = true
 }
 
-// GetLevel returns the current log level
-func (l *Logger) GetLevel() Level {
+// exitHandler returns the exit func in effect, nil when Fatal should return
+func (l *Logger) exitHandler() func(int) {
 	l.mu.RLock()
-	defer l.mu.RUnlock()
-	return l.level
+	fn, set := l.exitFunc, l.exitSet
+	l.mu.RUnlock()
+	if set {
+		return fn
+	}
+	if p := l.parentLogger(); p != nil && l.named {
+		return p.exitHandler()
+	}
+	return os.Exit
+}
+
+// exit closes every appender reachable from l and calls the exit func
+func (l *Logger) exit() {
+	fn := l.exitHandler()
+	if fn == nil {
+		return
+	}
+	for lg := l; lg != nil; lg = lg.parentLogger() {
+		_ = lg.Close()
+		if !lg.settings().additive {
+			break
+		}
+	}
+	fn(1)
+}
+
+// SetAdditive sets whether entries are also written to the parent's
+// appenders (default true), only meaningful for loggers from GetLogger
+func (l *Logger) SetAdditive(additive bool) {
+	l.configure(func(c *loggerConfig) {
+		c.additive = additive
+	})
+}
+
+// GetLevel returns the current log level, inherited from the parent unless set
+func (l *Logger) GetLevel() Level {
+	level := l.level.Load()
+	if level == levelInherit {
+		if p := l.parentLogger(); p != nil {
+			return p.GetLevel()
+		}
+		return INFO
+	}
+	return Level(level)
+}
+
+// parentLogger returns the parent in the logger hierarchy, the root
+// (global) logger for top-level names
+func (l *Logger) parentLogger() *Logger {
+	if l.parent != nil {
+		return l.parent
+	}
+	if l.named {
+		if root := globalLogger(); root != l {
+			return root
+		}
+	}
+	return nil
+}
+
+// locationEnabled reports whether this logger or an ancestor includes
+// caller location for entries at level
+func (l *Logger) locationEnabled(level Level) bool {
+	c := l.settings()
+	if !c.includeLocation && l.named {
+		if p := l.parentLogger(); p != nil {
+			return p.locationEnabled(level)
+		}
+	}
+	return c.includeLocation && level >= c.locationLevel
+}
+
+// WithCallerSkip returns a clone of l that skips skip frames above the
+// first caller outside this package when capturing the location, so a
+// facade wrapping the logger reports its own caller rather than itself.
+// l itself is left unchanged.
+func (l *Logger) WithCallerSkip(skip int) *Logger {
+	child := l.Clone()
+	child.skip.Store(int32(skip))
+	return child
+}
+
+// AddCallerSkip returns a clone of l skipping skip more frames when
+// capturing the location, for each wrapping layer. l itself is left
+// unchanged.
+func (l *Logger) AddCallerSkip(skip int) *Logger {
+	child := l.Clone()
+	child.skip.Add(int32(skip))
+	return child
+}
+
+func (l *Logger) callerSkip() int {
+	return int(l.skip.Load())
 }
 
 // AddAppender adds an appender to the logger
 func (l *Logger) AddAppender(appender Appender) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.appenders = append(l.appenders, appender)
+	old := *l.appenders.Load()
+	appenders := make([]Appender, len(old), len(old)+1)
+	copy(appenders, old)
+	appenders = append(appenders, appender)
+	l.appenders.Store(&appenders)
+}
+
+// loadAppenders returns the current appenders, the slice must not be modified
+func (l *Logger) loadAppenders() []Appender {
+	return *l.appenders.Load()
+}
+
+// GetAppender returns the appender with the given name, nil if none
+func (l *Logger) GetAppender(name string) Appender {
+	for _, a := range l.loadAppenders() {
+		if a.Name() == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// RemoveAppender detaches the named appender and returns it, nil if none.
+// It is not closed: entries being logged concurrently may still reach it,
+// so close it once those are done.
+func (l *Logger) RemoveAppender(name string) Appender {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := *l.appenders.Load()
+	for i, a := range old {
+		if a.Name() == name {
+			appenders := make([]Appender, 0, len(old)-1)
+			appenders = append(appenders, old[:i]...)
+			appenders = append(appenders, old[i+1:]...)
+			l.appenders.Store(&appenders)
+			return a
+		}
+	}
+	return nil
+}
+
+// ReplaceAppender swaps the named appender for appender in place, e.g. to
+// switch files at runtime, and returns the old one without closing it (see
+// RemoveAppender). Nothing changes and nil is returned when none is named so.
+func (l *Logger) ReplaceAppender(name string, appender Appender) Appender {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := *l.appenders.Load()
+	for i, a := range old {
+		if a.Name() == name {
+			appenders := make([]Appender, len(old))
+			copy(appenders, old)
+			appenders[i] = appender
+			l.appenders.Store(&appenders)
+			return a
+		}
+	}
+	return nil
+}
+
+// AddFilter adds a filter evaluated before any appender is invoked.
+// Filters run in order: DENY drops the entry, ACCEPT skips the remaining
+// logger filters, NEUTRAL defers to the next one.
+func (l *Logger) AddFilter(filter Filter) {
+	l.configure(func(c *loggerConfig) {
+		c.filters = append(slices.Clip(c.filters), filter)
+	})
+}
+
+// Hook is called with every entry before it reaches filters and appenders.
+// It may enrich or modify the entry, returning an error drops it.
+type Hook func(entry *Entry) error
+
+// AddHook adds a hook, hooks run in order after those of the ancestors of
+// a registry logger
+func (l *Logger) AddHook(hook Hook) {
+	l.configure(func(c *loggerConfig) {
+		c.hooks = append(slices.Clip(c.hooks), hook)
+	})
+}
+
+// runHooks runs the hooks of l and its ancestors, false means vetoed
+func (l *Logger) runHooks(entry *Entry) bool {
+	if l.named {
+		if p := l.parentLogger(); p != nil && !p.runHooks(entry) {
+			return false
+		}
+	}
+	for _, hook := range l.settings().hooks {
+		if hook(entry) != nil {
+			return false
+		}
+	}
+	return true
 }
 
 // MDC returns the MDC for context propagation
@@ -181,54 +550,259 @@ func (l *Logger) MDC() *MDC {
 	return l.mdc
 }
 
-// IsEnabled checks if a level is enabled
+// IsEnabled checks if a level is enabled, never for levels removed by the
+// logger_notrace and logger_nodebug build tags
 func (l *Logger) IsEnabled(level Level) bool {
-	return level >= l.GetLevel()
+	return !compiledOut(level) && level >= l.GetLevel()
+}
+
+// compiledOut reports whether a build tag removed level
+func compiledOut(level Level) bool {
+	return level == TRACE && !traceCompiled || level == DEBUG && !debugCompiled
+}
+
+// IsInfoEnabled checks if INFO is enabled
+func (l *Logger) IsInfoEnabled() bool { return l.IsEnabled(INFO) }
+
+// IsWarnEnabled checks if WARN is enabled
+func (l *Logger) IsWarnEnabled() bool { return l.IsEnabled(WARN) }
+
+// IsErrorEnabled checks if ERROR is enabled
+func (l *Logger) IsErrorEnabled() bool { return l.IsEnabled(ERROR) }
+
+// CheckedEntry is a guard returned by Check, a nil CheckedEntry writes nothing
+type CheckedEntry struct {
+	logger *Logger
+	level  Level
+}
+
+// Check returns a CheckedEntry when level is enabled and an appender may
+// accept it, nil otherwise, so arguments are only built when needed:
+//
+//	if ce := log.Check(DEBUG); ce != nil {
+//		ce.Write("cache state: %v", cache.Snapshot())
+//	}
+func (l *Logger) Check(level Level) *CheckedEntry {
+	if !l.IsEnabled(level) || !l.mayAccept(level) {
+		return nil
+	}
+	return &CheckedEntry{logger: l, level: level}
+}
+
+// Write logs a formatted message
+func (c *CheckedEntry) Write(format string, args ...interface{}) {
+	if c != nil {
+		c.logger.log(c.level, "", format, args...)
+	}
+}
+
+// Writew logs a message with alternating keys and values
+func (c *CheckedEntry) Writew(msg string, keysAndValues ...interface{}) {
+	if c != nil {
+		c.logger.logw(c.level, "", msg, keysAndValues)
+	}
+}
+
+// WriteFields logs a message with typed fields
+func (c *CheckedEntry) WriteFields(msg string, fields ...Field) {
+	if c != nil {
+		c.logger.logTyped(c.level, "", msg, fields)
+	}
 }
 
 // log is the internal logging method
 func (l *Logger) log(level Level, marker string, format string, args ...interface{}) {
-	if !l.IsEnabled(level) {
+	if !l.accepts(level) || !l.sampled(level, format) {
 		return
 	}
-
-	l.mu.RLock()
-	includeLocation := l.includeLocation
-	appenders := l.appenders
-	l.mu.RUnlock()
+	if hasLazyArgs(args) {
+		if !l.mayAccept(level) {
+			return
+		}
+		args = resolveLazyArgs(args)
+	}
 
 	var caller CallerInfo
-	if includeLocation {
-		caller = getCaller(4)
+	if l.locationEnabled(level) {
+		caller = getCaller(l.callerSkip())
 	}
 
-	entry := &Entry{
-		Time:    time.Now(),
-		Level:   level,
-		Message: fmt.Sprintf(format, args...),
-		Logger:  l.name,
-		Marker:  marker,
-		Context: l.mdc.Clone(),
-		Caller:  caller,
-		Fields:  make(map[string]interface{}),
+	entry := l.newEntry(level, marker, formatMessage(format, args), format)
+	entry.Caller = caller
+
+	if l.stackEnabled(level) {
+		entry.Stack = captureStack(2)
+	}
+
+	l.dispatch(entry)
+}
+
+// dispatch keeps entries below the level in the diagnostic buffer and
+// writes the rest, preceded by the buffered entries on errors
+func (l *Logger) dispatch(entry *Entry) {
+	defer entry.release()
+
+	buf := entry.diag
+	if buf == nil {
+		buf = l.diagnostics.Load()
+	}
+	if !l.IsEnabled(entry.Level) {
+		if buf.keeps(entry.Level) {
+			buf.keep(entry)
+		}
+		return
+	}
+	if buf != nil && entry.Level >= ERROR {
+		for _, kept := range buf.drain() {
+			l.write(kept)
+		}
+	}
+	l.write(entry)
+}
+
+// write runs the hooks and logger filters and hands the entry to every
+// appender
+func (l *Logger) write(entry *Entry) {
+	if !l.runHooks(entry) {
+		return
+	}
+
+filters:
+	for _, f := range l.settings().filters {
+		switch f.Decide(entry) {
+		case DENY:
+			return
+		case ACCEPT:
+			break filters
+		}
 	}
 
-	for _, appender := range appenders {
+	if l.sequenced.Load() {
+		entry.Seq = l.sequenceOwner().sequence.Add(1)
+	}
+	l.callAppenders(entry)
+}
+
+// SetGoroutineID records the ID of the logging goroutine in every entry,
+// for debugging concurrency issues. Off by default, as reading the ID
+// costs a short runtime.Stack call per entry.
+func (l *Logger) SetGoroutineID(enabled bool) {
+	l.goroutines.Store(enabled)
+}
+
+// SetSequence numbers the entries this logger accepts from 1, so consumers
+// can detect entries dropped or reordered after asynchronous buffering and
+// shipping. Additive children from GetLogger, Named and Clone share the
+// counter of the ancestor their entries end up at, so no appender sees a
+// number twice; the appenders of a child may see gaps where entries only
+// went to the ancestor.
+func (l *Logger) SetSequence(enabled bool) {
+	l.sequenced.Store(enabled)
+}
+
+// sequenceOwner returns the farthest ancestor entries of l reach through
+// additivity, whose counter numbers them
+func (l *Logger) sequenceOwner() *Logger {
+	owner := l
+	for owner.settings().additive {
+		p := owner.parentLogger()
+		if p == nil {
+			break
+		}
+		owner = p
+	}
+	return owner
+}
+
+// Flush synchronously drains the asynchronous appenders reachable from l
+// and flushes buffered writers, e.g. in a defer in main before exiting
+func (l *Logger) Flush() error {
+	var errs []error
+	for lg := l; lg != nil; lg = lg.parentLogger() {
+		additive := lg.settings().additive
+		for _, a := range lg.loadAppenders() {
+			if f, ok := a.(Flusher); ok {
+				if err := f.Flush(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		if !additive {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// callAppenders writes to this logger's appenders and, while additive,
+// to those of its ancestors
+func (l *Logger) callAppenders(entry *Entry) {
+	if l.shutdown.Load() {
+		return
+	}
+
+	for _, appender := range l.loadAppenders() {
 		_ = appender.Append(entry)
 	}
+	if l.settings().additive {
+		if p := l.parentLogger(); p != nil {
+			p.callAppenders(entry)
+		}
+	}
 }
 
-// Trace logs at TRACE level
-func (l *Logger) Trace(format string, args ...interface{}) {
-	l.log(TRACE, "", format, args...)
+// logw is the internal key-value logging method, msg is used verbatim
+func (l *Logger) logw(level Level, marker string, msg string, keysAndValues []interface{}) {
+	if !l.accepts(level) || !l.sampled(level, msg) {
+		return
+	}
+
+	var caller CallerInfo
+	if l.locationEnabled(level) {
+		caller = getCaller(l.callerSkip())
+	}
+
+	entry := l.newEntry(level, marker, msg, msg)
+	entry.Caller = caller
+	sweetenFields(entry.Fields, keysAndValues)
+
+	if l.stackEnabled(level) {
+		entry.Stack = captureStack(2)
+	}
+
+	l.dispatch(entry)
 }
 
-// Debug logs at DEBUG level
-func (l *Logger) Debug(format string, args ...interface{}) {
-	l.log(DEBUG, "", format, args...)
+// logTyped is the internal typed-field logging method, msg is used verbatim
+func (l *Logger) logTyped(level Level, marker string, msg string, fields []Field) {
+	if !l.accepts(level) || !l.sampled(level, msg) {
+		return
+	}
+
+	var caller CallerInfo
+	if l.locationEnabled(level) {
+		caller = getCaller(l.callerSkip())
+	}
+
+	entry := l.newEntry(level, marker, msg, msg)
+	entry.Caller = caller
+	entry.Typed = fields
+
+	if l.stackEnabled(level) {
+		entry.Stack = captureStack(2)
+	}
+
+	l.dispatch(entry)
+}
+
+// Log logs msg at level with typed fields, the low-allocation alternative
+// to WithFields for hot paths
+func (l *Logger) Log(level Level, msg string, fields ...Field) {
+	l.logTyped(level, "", msg, fields)
 }
 
-// Info logs at INFO level
+// Info logs at INFO level. format takes fmt verbs or, when it has no %,
+// SLF4J-style {} placeholders, see formatMessage
 func (l *Logger) Info(format string, args ...interface{}) {
 	l.log(INFO, "", format, args...)
 }
@@ -243,14 +817,114 @@ func (l *Logger) Error(format string, args ...interface{}) {
 	l.log(ERROR, "", format, args...)
 }
 
-// Fatal logs at FATAL level
+// Panic logs at PANIC level, flushes the appenders and panics with the message
+func (l *Logger) Panic(format string, args ...interface{}) {
+	l.log(PANIC, "", format, args...)
+	_ = l.Flush()
+	panic(formatMessage(format, args))
+}
+
+// Panicf is Panic, for code written against logrus or zap's sugared logger
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	l.Panic(format, args...)
+}
+
+// Fatal logs at FATAL level, closes the appenders and exits, see SetExitFunc
 func (l *Logger) Fatal(format string, args ...interface{}) {
 	l.log(FATAL, "", format, args...)
+	l.exit()
+}
+
+// Infow logs a message with alternating keys and values at INFO level,
+// e.g. Infow("user login", "user", id, "ip", ip)
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.logw(INFO, "", msg, keysAndValues)
+}
+
+// Warnw logs a message with alternating keys and values at WARN level
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.logw(WARN, "", msg, keysAndValues)
+}
+
+// Errorw logs a message with alternating keys and values at ERROR level
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.logw(ERROR, "", msg, keysAndValues)
+}
+
+// Panicw logs a message with alternating keys and values at PANIC level, then panics
+func (l *Logger) Panicw(msg string, keysAndValues ...interface{}) {
+	l.logw(PANIC, "", msg, keysAndValues)
+	_ = l.Flush()
+	panic(msg)
+}
+
+// Fatalw logs a message with alternating keys and values at FATAL level
+func (l *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.logw(FATAL, "", msg, keysAndValues)
+	l.exit()
+}
+
+// logln logs args joined like fmt.Sprint or, with spaces, fmt.Sprintln
+func (l *Logger) logln(level Level, spaced bool, args []interface{}) {
+	if !l.accepts(level) {
+		return
+	}
+	if hasLazyArgs(args) {
+		if !l.mayAccept(level) {
+			return
+		}
+		args = resolveLazyArgs(args)
+	}
+	var msg string
+	if spaced {
+		msg = fmt.Sprintln(args...)
+		msg = msg[:len(msg)-1]
+	} else {
+		msg = fmt.Sprint(args...)
+	}
+	l.logw(level, "", msg, nil)
+}
+
+// Infoln logs its arguments separated by spaces at INFO level,
+// e.g. Infoln("listening on", addr)
+func (l *Logger) Infoln(args ...interface{}) {
+	l.logln(INFO, true, args)
+}
+
+// Warnln logs its arguments separated by spaces at WARN level
+func (l *Logger) Warnln(args ...interface{}) {
+	l.logln(WARN, true, args)
+}
+
+// Errorln logs its arguments separated by spaces at ERROR level
+func (l *Logger) Errorln(args ...interface{}) {
+	l.logln(ERROR, true, args)
+}
+
+// Fatalln logs its arguments separated by spaces at FATAL level
+func (l *Logger) Fatalln(args ...interface{}) {
+	l.logln(FATAL, true, args)
+	l.exit()
+}
+
+// Print logs at INFO level like log.Print
+func (l *Logger) Print(args ...interface{}) {
+	l.logln(INFO, false, args)
+}
+
+// Printf logs at INFO level like log.Printf
+func (l *Logger) Printf(format string, args ...interface{}) {
+	l.log(INFO, "", format, args...)
+}
+
+// Println logs at INFO level like log.Println
+func (l *Logger) Println(args ...interface{}) {
+	l.logln(INFO, true, args)
 }
 
 // WithMarker returns a MarkerLogger for categorized logging
 func (l *Logger) WithMarker(marker string) *MarkerLogger {
-	return &MarkerLogger{logger: l, marker: marker}
+	return &MarkerLogger{FieldLogger{logger: l, marker: marker}}
 }
 
 // WithContext adds context and returns the logger for chaining
@@ -264,83 +938,206 @@ func (l *Logger) WithFields(fields map[string]interface{}) *FieldLogger {
 	return &FieldLogger{logger: l, fields: fields}
 }
 
-// WithError logs with error
+// WithError logs with error, rendered with its wrapped causes and stack traces
 func (l *Logger) WithError(err error) *FieldLogger {
-	return &FieldLogger{logger: l, fields: map[string]interface{}{"error": err}}
+	return &FieldLogger{logger: l, err: err}
+}
+
+// WithCode logs with a stable event code such as "AUTH_001", for alerting
+// and translations keyed by code rather than by message text
+func (l *Logger) WithCode(code string) *FieldLogger {
+	return &FieldLogger{logger: l, code: code}
+}
+
+// ErrorE logs at ERROR level with err attached to the entry
+func (l *Logger) ErrorE(err error, format string, args ...interface{}) {
+	l.WithError(err).log(ERROR, format, args...)
+}
+
+// Reopen reopens all file-based appenders
+func (l *Logger) Reopen() error {
+	var errs []error
+	for _, appender := range l.loadAppenders() {
+		if r, ok := appender.(Reopener); ok {
+			if err := r.Reopen(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // Close closes all appenders
 func (l *Logger) Close() error {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	for _, appender := range l.appenders {
+	for _, appender := range l.loadAppenders() {
 		_ = appender.Close()
 	}
 	return nil
 }
 
-// MarkerLogger wraps logger with a marker
-type MarkerLogger struct {
-	logger *Logger
-	marker string
-}
-
-func (m *MarkerLogger) Trace(format string, args ...interface{}) {
-	m.logger.log(TRACE, m.marker, format, args...)
+// ShutdownError reports entries still queued when the shutdown deadline passed
+type ShutdownError struct {
+	Dropped uint64
+	Err     error
 }
 
-func (m *MarkerLogger) Debug(format string, args ...interface{}) {
-	m.logger.log(DEBUG, m.marker, format, args...)
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("logger: shutdown dropped %d entries: %v", e.Dropped, e.Err)
 }
 
-func (m *MarkerLogger) Info(format string, args ...interface{}) {
-	m.logger.log(INFO, m.marker, format, args...)
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
 }
 
-func (m *MarkerLogger) Warn(format string, args ...interface{}) {
-	m.logger.log(WARN, m.marker, format, args...)
+// Shutdown stops accepting entries, writes what asynchronous appenders
+// have queued until ctx is done and closes the appenders. Entries still
+// queued at the deadline are dropped and reported as a *ShutdownError.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	l.shutdown.Store(true)
+
+	var errs []error
+	var dropped uint64
+	for _, appender := range l.loadAppenders() {
+		var err error
+		if async, ok := appender.(*AsyncAppender); ok {
+			before := async.Dropped()
+			err = async.Shutdown(ctx)
+			dropped += async.Dropped() - before
+		} else {
+			if f, ok := appender.(Flusher); ok {
+				_ = f.Flush()
+			}
+			err = appender.Close()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if dropped > 0 {
+		errs = append(errs, &ShutdownError{Dropped: dropped, Err: ctx.Err()})
+	}
+	return errors.Join(errs...)
 }
 
-func (m *MarkerLogger) Error(format string, args ...interface{}) {
-	m.logger.log(ERROR, m.marker, format, args...)
+// MarkerLogger wraps logger with a marker, fields and an error can be
+// added as with FieldLogger
+type MarkerLogger struct {
+	FieldLogger
 }
 
 // FieldLogger wraps logger with additional fields
 type FieldLogger struct {
 	logger *Logger
 	fields map[string]interface{}
+	err    error
+	marker string
+	code   string
+	ctx    context.Context // see ContextLogger
 }
 
 func (f *FieldLogger) log(level Level, format string, args ...interface{}) {
-	if !f.logger.IsEnabled(level) {
+	if !f.accepts(level) || !f.logger.sampled(level, format) {
 		return
 	}
+	if hasLazyArgs(args) {
+		if !f.logger.mayAccept(level) {
+			return
+		}
+		args = resolveLazyArgs(args)
+	}
+
+	entry := f.logger.newEntry(level, f.marker, formatMessage(format, args), format)
+	if f.logger.locationEnabled(level) {
+		entry.Caller = getCaller(f.logger.callerSkip())
+	}
+	for k, v := range f.fields {
+		entry.Fields[k] = v
+	}
+	f.decorate(entry)
 
-	entry := &Entry{
-		Time:    time.Now(),
-		Level:   level,
-		Message: fmt.Sprintf(format, args...),
-		Logger:  f.logger.name,
-		Context: f.logger.mdc.Clone(),
-		Caller:  getCaller(4),
-		Fields:  f.fields,
+	if f.logger.stackEnabled(level) {
+		entry.Stack = captureStack(2)
 	}
 
-	f.logger.mu.RLock()
-	appenders := f.logger.appenders
-	f.logger.mu.RUnlock()
+	f.logger.dispatch(entry)
+}
 
-	for _, appender := range appenders {
-		_ = appender.Append(entry)
+// accepts reports whether entries at level are written or kept in the
+// diagnostic buffer of the logger or of the context
+func (f *FieldLogger) accepts(level Level) bool {
+	if compiledOut(level) {
+		return false
 	}
+	return f.logger.accepts(level) || f.ctx != nil && diagnosticBufferFrom(f.ctx).keeps(level)
 }
 
-func (f *FieldLogger) Trace(format string, args ...interface{}) {
-	f.log(TRACE, format, args...)
+// logw logs msg verbatim with the fields of f and keysAndValues
+func (f *FieldLogger) logw(level Level, msg string, keysAndValues []interface{}) {
+	if !f.accepts(level) || !f.logger.sampled(level, msg) {
+		return
+	}
+
+	entry := f.logger.newEntry(level, f.marker, msg, msg)
+	if f.logger.locationEnabled(level) {
+		entry.Caller = getCaller(f.logger.callerSkip())
+	}
+	for k, v := range f.fields {
+		entry.Fields[k] = v
+	}
+	sweetenFields(entry.Fields, keysAndValues)
+	f.decorate(entry)
+
+	if f.logger.stackEnabled(level) {
+		entry.Stack = captureStack(2)
+	}
+
+	f.logger.dispatch(entry)
 }
 
-func (f *FieldLogger) Debug(format string, args ...interface{}) {
-	f.log(DEBUG, format, args...)
+// decorate sets the error and code of f and the values found in its context
+func (f *FieldLogger) decorate(entry *Entry) {
+	entry.Error = f.err
+	entry.Code = f.code
+	if f.ctx != nil {
+		entry.diag = diagnosticBufferFrom(f.ctx)
+		extractSpanContext(f.ctx, entry.Context)
+		f.logger.extractContext(f.ctx, entry.Context)
+	}
+}
+
+// Log logs msg at level with typed fields, next to the fields of f
+func (f *FieldLogger) Log(level Level, msg string, fields ...Field) {
+	if !f.accepts(level) || !f.logger.sampled(level, msg) {
+		return
+	}
+
+	entry := f.logger.newEntry(level, f.marker, msg, msg)
+	if f.logger.locationEnabled(level) {
+		entry.Caller = getCaller(f.logger.callerSkip())
+	}
+	for k, v := range f.fields {
+		entry.Fields[k] = v
+	}
+	entry.Typed = fields
+	f.decorate(entry)
+
+	if f.logger.stackEnabled(level) {
+		entry.Stack = captureStack(2)
+	}
+
+	f.logger.dispatch(entry)
+}
+
+func (f *FieldLogger) Infow(msg string, keysAndValues ...interface{}) {
+	f.logw(INFO, msg, keysAndValues)
+}
+
+func (f *FieldLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	f.logw(WARN, msg, keysAndValues)
+}
+
+func (f *FieldLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	f.logw(ERROR, msg, keysAndValues)
 }
 
 func (f *FieldLogger) Info(format string, args ...interface{}) {
@@ -355,8 +1152,20 @@ func (f *FieldLogger) Error(format string, args ...interface{}) {
 	f.log(ERROR, format, args...)
 }
 
+func (f *FieldLogger) Panic(format string, args ...interface{}) {
+	f.log(PANIC, format, args...)
+	_ = f.logger.Flush()
+	panic(formatMessage(format, args))
+}
+
+// Panicf is Panic, for code written against logrus or zap's sugared logger
+func (f *FieldLogger) Panicf(format string, args ...interface{}) {
+	f.Panic(format, args...)
+}
+
 func (f *FieldLogger) Fatal(format string, args ...interface{}) {
 	f.log(FATAL, format, args...)
+	f.logger.exit()
 }
 
 // WithFields adds more fields to the existing FieldLogger
@@ -368,53 +1177,124 @@ func (f *FieldLogger) WithFields(fields map[string]interface{}) *FieldLogger {
 	for k, v := range fields {
 		newFields[k] = v
 	}
-	return &FieldLogger{logger: f.logger, fields: newFields}
+	c := *f
+	c.fields = newFields
+	return &c
 }
 
-// WithError adds error to the existing FieldLogger
+// WithError sets the error of the existing FieldLogger
 func (f *FieldLogger) WithError(err error) *FieldLogger {
-	return f.WithFields(map[string]interface{}{"error": err})
+	c := *f
+	c.err = err
+	return &c
 }
 
-// getCaller retrieves caller information
-func getCaller(skip int) CallerInfo {
-	pc, file, line, ok := runtime.Caller(skip)
-	if !ok {
-		return CallerInfo{}
-	}
-	fn := runtime.FuncForPC(pc)
-	funcName := ""
-	if fn != nil {
-		funcName = fn.Name()
-	}
-	// Extract just the file name
-	for i := len(file) - 1; i >= 0; i-- {
-		if file[i] == '/' || file[i] == '\\' {
-			file = file[i+1:]
+// WithCode sets a stable event code, keeping the fields, error and marker
+func (f *FieldLogger) WithCode(code string) *FieldLogger {
+	c := *f
+	c.code = code
+	return &c
+}
+
+// WithMarker adds a marker, keeping the fields and error
+func (f *FieldLogger) WithMarker(marker string) *MarkerLogger {
+	c := *f
+	c.marker = marker
+	return &MarkerLogger{c}
+}
+
+// WithCtx binds ctx, keeping the fields, error and marker
+func (f *FieldLogger) WithCtx(ctx context.Context) *ContextLogger {
+	c := *f
+	c.ctx = ctx
+	return &ContextLogger{c}
+}
+
+// sweetenFields turns alternating keys and values into fields. A Field
+// counts as a pair of its own, keys that are not strings are formatted,
+// a trailing value without a key is kept under "!BADKEY".
+func sweetenFields(fields map[string]interface{}, keysAndValues []interface{}) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if f, ok := keysAndValues[i].(Field); ok {
+			if f.Key != "" {
+				fields[f.Key] = f.Value()
+			}
+			i--
+			continue
+		}
+		if i+1 == len(keysAndValues) {
+			fields["!BADKEY"] = keysAndValues[i]
 			break
 		}
-	}
-	return CallerInfo{
-		File:     file,
-		Line:     line,
-		Function: funcName,
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		fields[key] = keysAndValues[i+1]
 	}
 }
 
-// Context-aware logging
-type ContextLogger struct {
-	logger *Logger
-	ctx    context.Context
+// loggerPackage prefixes the function names of this package, frames in it
+// are skipped when looking for the caller
+var loggerPackage = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name() // e.g. "example.com/logger.init.func1"
+	pkg := strings.LastIndex(name, "/") + 1
+	return name[:pkg+strings.Index(name[pkg:], ".")+1]
+}()
+
+// callerFrame is a resolved stack frame, cached by program counter
+type callerFrame struct {
+	info     CallerInfo
+	internal bool // in this package, outside tests
 }
 
-func (l *Logger) WithCtx(ctx context.Context) *ContextLogger {
-	return &ContextLogger{logger: l, ctx: ctx}
-}
+// callerCache maps a program counter to its frames, several when calls
+// were inlined. Call sites are finite, so it stays bounded.
+var callerCache sync.Map
 
-func (c *ContextLogger) Info(format string, args ...interface{}) {
-	c.logger.Info(format, args...)
+// framesForPC resolves pc once, runtime.CallersFrames and the symbol
+// lookup behind it are the costly part of capturing the location
+func framesForPC(pc uintptr) []callerFrame {
+	if cached, ok := callerCache.Load(pc); ok {
+		return cached.([]callerFrame)
+	}
+	var resolved []callerFrame
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := frames.Next()
+		resolved = append(resolved, callerFrame{
+			info: CallerInfo{
+				File:     shortFile(frame.File),
+				Line:     frame.Line,
+				Function: frame.Function,
+			},
+			internal: strings.HasPrefix(frame.Function, loggerPackage) && !strings.HasSuffix(frame.File, "_test.go"),
+		})
+		if !more {
+			break
+		}
+	}
+	callerCache.Store(pc, resolved)
+	return resolved
 }
 
-func (c *ContextLogger) Error(format string, args ...interface{}) {
-	c.logger.Error(format, args...)
+// getCaller retrieves caller information: the first frame outside this
+// package, so package functions and helpers report their own caller,
+// followed by skip more frames for wrappers (see WithCallerSkip)
+func getCaller(skip int) CallerInfo {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	for _, pc := range pcs[:n] {
+		for _, frame := range framesForPC(pc) {
+			if frame.internal {
+				continue
+			}
+			if skip == 0 {
+				return frame.info
+			}
+			skip--
+		}
+	}
+	return CallerInfo{}
 }
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	markerMu      sync.RWMutex
+	markerParents = make(map[string][]string)
+)
+
+// SetMarkerParents declares parents of a marker in addition to the ones
+// implied by its dotted name ("SQL.SLOW" is always a child of "SQL").
+// Names are case-insensitive.
+func SetMarkerParents(marker string, parents ...string) {
+	markerMu.Lock()
+	defer markerMu.Unlock()
+	key := strings.ToUpper(marker)
+	for _, p := range parents {
+		markerParents[key] = append(markerParents[key], strings.ToUpper(p))
+	}
+}
+
+// MarkerIsInstanceOf reports whether marker is ancestor or one of its descendants
+func MarkerIsInstanceOf(marker, ancestor string) bool {
+	if marker == "" || ancestor == "" {
+		return false
+	}
+	markerMu.RLock()
+	defer markerMu.RUnlock()
+	return markerDescends(strings.ToUpper(marker), strings.ToUpper(ancestor), 0)
+}
+
+// markerDescends walks dotted and declared parents, depth limited against cycles
+func markerDescends(marker, ancestor string, depth int) bool {
+	if marker == ancestor {
+		return true
+	}
+	if depth > 16 {
+		return false
+	}
+	if i := strings.LastIndexByte(marker, '.'); i > 0 && markerDescends(marker[:i], ancestor, depth+1) {
+		return true
+	}
+	for _, p := range markerParents[marker] {
+		if markerDescends(p, ancestor, depth+1) {
+			return true
+		}
+	}
+	return false
+}
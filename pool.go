@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxPooledMapSize keeps entries that carried unusually many fields or
+// context values from pinning large maps in the pool
+const maxPooledMapSize = 64
+
+var entryPool = sync.Pool{
+	New: func() interface{} {
+		return &Entry{
+			context: make(map[string]interface{}),
+			fields:  make(map[string]interface{}),
+		}
+	},
+}
+
+// newEntry takes an entry from the pool with Context filled from the MDC
+// and Fields holding the global fields. The entry returns to the pool once the logger
+// and every appender holding it have called release, so appenders and
+// hooks must not keep it, or its maps, after Append returns.
+func (l *Logger) newEntry(level Level, marker string, message, template string) *Entry {
+	e := entryPool.Get().(*Entry)
+	e.refs = 1
+	e.Time = time.Now()
+	e.Level = level
+	e.Message = message
+	e.Template = template
+	e.Logger = l.name
+	e.Marker = marker
+	l.mdc.copyTo(e.context)
+	addGlobalFields(e.fields)
+	e.Context = e.context
+	e.Fields = e.fields
+	if l.goroutines.Load() {
+		e.Goroutine = goroutineID()
+	}
+	return e
+}
+
+// cloneEntry copies an entry, including its maps, for keeping it after
+// Append returns. The copy is not pooled.
+func cloneEntry(e *Entry) *Entry {
+	c := *e
+	c.refs, c.context, c.fields = 0, nil, nil
+	if e.Context != nil {
+		c.Context = make(map[string]interface{}, len(e.Context))
+		for k, v := range e.Context {
+			c.Context[k] = v
+		}
+	}
+	if e.Fields != nil {
+		c.Fields = make(map[string]interface{}, len(e.Fields))
+		for k, v := range e.Fields {
+			c.Fields[k] = v
+		}
+	}
+	c.Typed = append([]Field(nil), e.Typed...)
+	return &c
+}
+
+// retain keeps a pooled entry alive past Append, e.g. while queued
+func (e *Entry) retain() {
+	if e.context != nil {
+		atomic.AddInt32(&e.refs, 1)
+	}
+}
+
+// release drops a reference, the last one returns the entry to the pool.
+// Entries not created by newEntry are left alone.
+func (e *Entry) release() {
+	if e.context == nil || atomic.AddInt32(&e.refs, -1) != 0 {
+		return
+	}
+	context, fields := e.context, e.fields
+	if len(context) > maxPooledMapSize {
+		context = make(map[string]interface{})
+	} else {
+		clear(context)
+	}
+	if len(fields) > maxPooledMapSize {
+		fields = make(map[string]interface{})
+	} else {
+		clear(fields)
+	}
+	*e = Entry{context: context, fields: fields}
+	entryPool.Put(e)
+}
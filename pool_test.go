@@ -0,0 +1,67 @@
+package logger
+
+import "testing"
+
+// TestEntryRefcount checks that a pooled entry is only reset once every
+// holder released it
+func TestEntryRefcount(t *testing.T) {
+	tests := []struct {
+		name     string
+		retains  int
+		releases int
+		alive    bool
+	}{
+		{"logger only", 0, 1, false},
+		{"retained, logger released", 1, 1, true},
+		{"retained and released", 1, 2, false},
+		{"retained twice, one left", 2, 2, true},
+		{"retained twice, all released", 2, 3, false},
+	}
+	l := NewLogger("pool")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := l.newEntry(INFO, "", "message", "")
+			e.Fields["key"] = "value"
+			for i := 0; i < tt.retains; i++ {
+				e.retain()
+			}
+			for i := 0; i < tt.releases; i++ {
+				e.release()
+			}
+			if alive := e.Message == "message" && e.Fields["key"] == "value"; alive != tt.alive {
+				t.Errorf("alive = %v, want %v", alive, tt.alive)
+			}
+		})
+	}
+}
+
+// TestEntryReleaseUnpooled leaves entries not created by newEntry alone
+func TestEntryReleaseUnpooled(t *testing.T) {
+	e := &Entry{Message: "message", Fields: map[string]interface{}{"key": "value"}}
+	e.retain()
+	e.release()
+	e.release()
+	if e.Message != "message" || e.Fields["key"] != "value" {
+		t.Errorf("unpooled entry was reset: %+v", e)
+	}
+}
+
+// TestCloneEntrySurvivesRelease keeps a clone intact once the original
+// returned to the pool
+func TestCloneEntrySurvivesRelease(t *testing.T) {
+	l := NewLogger("pool")
+	e := l.newEntry(INFO, "", "message", "")
+	e.Fields["key"] = "value"
+	e.Context["trace"] = "abc"
+
+	c := cloneEntry(e)
+	e.release()
+
+	if c.Message != "message" || c.Fields["key"] != "value" || c.Context["trace"] != "abc" {
+		t.Errorf("clone changed after release: %+v", c)
+	}
+	c.release() // not pooled, must be a no-op
+	if c.Message != "message" {
+		t.Errorf("clone was reset by release")
+	}
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Buffering of the default global logger, off unless SetPreInitBuffer
+// is called
+var (
+	preInitSize int
+	preInitWait time.Duration
+)
+
+// SetPreInitBuffer keeps up to size entries logged before Init, to be
+// replayed through the configured appenders once Init runs, instead of
+// writing them to the console. After wait without Init, when the buffer
+// is full, or on Flush, Shutdown or Fatal, they go to the console after
+// all. Size 0, the default, writes to the console right away. Call it
+// before anything is logged, e.g. in an init func, and make sure main
+// calls Init or Flush before returning:
+//
+//	logger.SetPreInitBuffer(1000, 10*time.Second)
+func SetPreInitBuffer(size int, wait time.Duration) {
+	preInitSize = size
+	preInitWait = wait
+}
+
+// preInitAppender holds the entries of the default global logger until
+// Init installs the configured logger, see setGlobal. When Init doesn't
+// come in time, the buffer fills up or the logger is flushed, it gives up
+// and writes to the console like the default logger would.
+type preInitAppender struct {
+	mu      sync.Mutex
+	entries []*Entry
+	size    int
+	wait    time.Duration
+	timer   *time.Timer
+	target  *Logger  // set by Init, later entries are forwarded
+	console Appender // set when giving up on Init
+}
+
+func newPreInitAppender(size int, wait time.Duration) *preInitAppender {
+	return &preInitAppender{size: size, wait: wait}
+}
+
+func (p *preInitAppender) Name() string {
+	return "PreInit"
+}
+
+func (p *preInitAppender) Append(entry *Entry) error {
+	p.mu.Lock()
+	if target := p.target; target != nil {
+		p.mu.Unlock()
+		target.replay(cloneEntry(entry))
+		return nil
+	}
+	if console := p.console; console != nil {
+		p.mu.Unlock()
+		return console.Append(entry)
+	}
+	p.entries = append(p.entries, cloneEntry(entry))
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.wait, func() { _ = p.Flush() })
+	}
+	full := len(p.entries) >= p.size
+	p.mu.Unlock()
+
+	if full {
+		return p.Flush()
+	}
+	return nil
+}
+
+// Flush stops waiting for Init and writes the buffered entries to the console
+func (p *preInitAppender) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.target != nil {
+		return nil
+	}
+	if p.console == nil {
+		p.console = NewConsoleAppender()
+		p.stopTimer()
+	}
+	for _, e := range p.entries {
+		_ = p.console.Append(e)
+	}
+	p.entries = nil
+	return nil
+}
+
+// Close writes what is still buffered, e.g. when Fatal exits before Init
+func (p *preInitAppender) Close() error {
+	return p.Flush()
+}
+
+// replayTo dispatches the buffered entries to l and forwards later ones
+func (p *preInitAppender) replayTo(l *Logger) {
+	p.mu.Lock()
+	entries := p.entries
+	p.entries = nil
+	p.target = l
+	p.stopTimer()
+	p.mu.Unlock()
+
+	for _, e := range entries {
+		l.replay(e)
+	}
+}
+
+func (p *preInitAppender) stopTimer() {
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+// replay dispatches an entry logged elsewhere, subject to l's level
+func (l *Logger) replay(entry *Entry) {
+	if l.IsEnabled(entry.Level) {
+		l.dispatch(entry)
+	}
+}
+
+// setGlobal installs l as the global logger, replaying what the default
+// logger buffered before Init
+func setGlobal(l *Logger) {
+	old := global.Swap(l)
+	if old == nil {
+		return
+	}
+	for _, a := range old.loadAppenders() {
+		if p, ok := a.(*preInitAppender); ok {
+			p.replayTo(l)
+		}
+	}
+}
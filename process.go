@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+var (
+	pidString = strconv.Itoa(os.Getpid())
+
+	hostnameOnce  sync.Once
+	hostnameValue string
+
+	executableOnce  sync.Once
+	executableValue string
+)
+
+// cachedHostname returns the hostname, looked up once
+func cachedHostname() string {
+	hostnameOnce.Do(func() {
+		hostnameValue, _ = os.Hostname()
+	})
+	return hostnameValue
+}
+
+// cachedExecutable returns the base name of the executable, looked up once
+func cachedExecutable() string {
+	executableOnce.Do(func() {
+		path, err := os.Executable()
+		if err != nil && len(os.Args) > 0 {
+			path = os.Args[0]
+		}
+		executableValue = filepath.Base(path)
+	})
+	return executableValue
+}
+
+// ProcessMetadata returns a hook adding hostname, pid and executable fields
+// to every entry, for aggregating logs of many hosts. The values are looked
+// up once, fields set at the call site are kept.
+func ProcessMetadata() Hook {
+	metadata := map[string]interface{}{
+		"hostname":   cachedHostname(),
+		"pid":        os.Getpid(),
+		"executable": cachedExecutable(),
+	}
+	return func(entry *Entry) error {
+		for k, v := range metadata {
+			if _, ok := entry.Fields[k]; !ok {
+				entry.Fields[k] = v
+			}
+		}
+		return nil
+	}
+}
+
+// goroutineID returns the current goroutine ID, parsed from the
+// "goroutine N [" header of runtime.Stack
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	const prefix = "goroutine "
+	if len(b) <= len(prefix) {
+		return 0
+	}
+	var id uint64
+	for _, c := range b[len(prefix):] {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+	return id
+}
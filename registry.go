@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+)
+
+// RootLoggerName is the name of the global logger at the top of the hierarchy
+const RootLoggerName = "root"
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Logger)
+)
+
+// GetLogger returns the global (root) logger, a console logger at INFO when
+// Init was never called, or with a name the registry logger of that name,
+// creating it and its ancestors if needed.
+//
+// Names form a hierarchy on "." and "/": "app.db" is a child of "app",
+// "github.com/acme/app/db" of "github.com/acme/app". A registry logger has
+// no appenders of its own until AddAppender, inherits its level from the
+// nearest ancestor with one set (so does the stack trace level) and, while
+// additive, also writes to the appenders of its ancestors up to the root.
+func GetLogger(name ...string) *Logger {
+	if len(name) == 0 || name[0] == "" || name[0] == RootLoggerName {
+		return globalLogger()
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return registeredLogger(name[0])
+}
+
+// registeredLogger looks up or creates a named logger, registryMu must be held
+func registeredLogger(name string) *Logger {
+	if l, ok := registry[name]; ok {
+		return l
+	}
+	l := NewLogger(name)
+	l.named = true
+	l.level.Store(levelInherit)
+	l.configure(func(c *loggerConfig) {
+		c.stackSet = false
+	})
+	if p := parentLoggerName(name); p != "" {
+		l.parent = registeredLogger(p)
+	}
+	registry[name] = l
+	return l
+}
+
+// Named returns a child logger called "<name>.<sub>" that shares the
+// appenders of l without registering it globally, so a library given a
+// *Logger can scope it. The child inherits the level and other settings
+// of l until set on the child, and starts with a copy of its MDC and
+// logger filters.
+func (l *Logger) Named(sub string) *Logger {
+	name := sub
+	if l.name != "" && l.name != RootLoggerName {
+		name = l.name + "." + sub
+	}
+	return l.derive(name)
+}
+
+// Clone returns a child logger like Named, keeping the name of l
+func (l *Logger) Clone() *Logger {
+	return l.derive(l.name)
+}
+
+func (l *Logger) derive(name string) *Logger {
+	child := NewLogger(name)
+	child.named = true
+	child.parent = l
+	child.level.Store(levelInherit)
+	child.skip.Store(l.skip.Load())
+	child.sequenced.Store(l.sequenced.Load())
+	child.goroutines.Store(l.goroutines.Load())
+	child.diagnostics.Store(l.diagnostics.Load())
+
+	child.configure(func(c *loggerConfig) {
+		c.stackSet = false
+		c.filters = l.settings().filters
+	})
+	l.mdc.copyTo(child.mdc.data)
+	return child
+}
+
+// parentLoggerName strips the last name segment, "" for top-level names
+func parentLoggerName(name string) string {
+	if i := strings.LastIndexAny(name, "./"); i > 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// SetLevelFor sets the level of the named logger and, through inheritance,
+// of its descendants that have no level of their own
+func SetLevelFor(name string, level Level) {
+	if l := GetLogger(name); l != nil {
+		l.SetLevel(level)
+	}
+}
+
+// ClearLevelFor makes the named logger inherit its level again
+func ClearLevelFor(name string) {
+	if l := GetLogger(name); l != nil {
+		l.ClearLevel()
+	}
+}
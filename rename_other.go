@@ -0,0 +1,9 @@
+//go:build !windows
+
+package logger
+
+// isTransientRenameError reports whether a rename is worth retrying, never
+// outside Windows where open files do not block renames
+func isTransientRenameError(err error) bool {
+	return false
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package logger
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errorSharingViolation is ERROR_SHARING_VIOLATION, missing from syscall
+const errorSharingViolation syscall.Errno = 32
+
+// isTransientRenameError reports whether a rename failed because another
+// process, typically antivirus or an indexer, briefly holds the file
+func isTransientRenameError(err error) bool {
+	return errors.Is(err, errorSharingViolation) || errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+}
@@ -1,11 +1,15 @@
 package logger
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -46,52 +50,144 @@ func (p *SizeBasedPolicy) GetNextFileName(baseName string, index int) string {
 	return fmt.Sprintf("%s.%d%s", name, index, ext)
 }
 
-// TimeBasedPolicy triggers rollover based on time
+// TimeBasedPolicy triggers rollover at calendar boundaries: the top of the
+// hour, midnight, or the start of the week in the configured timezone
 type TimeBasedPolicy struct {
-	interval time.Duration
-	pattern  string // date pattern for file naming
-	lastRoll time.Time
+	interval  string         // hourly, daily, weekly
+	pattern   string         // date pattern for file naming
+	location  *time.Location // timezone boundaries are aligned to
+	weekStart time.Weekday
+	modulate  bool          // align to calendar boundaries rather than start time
+	offset    time.Duration // shift boundaries, e.g. 4h rolls daily at 04:00
+	origin    time.Time     // reference point when not modulated
+	current   time.Time     // start of the period being written
+	ended     time.Time     // start of the period that was just rolled
+	started   bool
 }
 
 // NewTimeBasedPolicy creates a time-based rolling policy
 // interval examples: "hourly", "daily", "weekly"
 func NewTimeBasedPolicy(interval string) *TimeBasedPolicy {
-	var d time.Duration
 	var pattern string
 
 	switch interval {
 	case "hourly":
-		d = time.Hour
 		pattern = "2006-01-02-15"
-	case "daily":
-		d = 24 * time.Hour
-		pattern = "2006-01-02"
-	case "weekly":
-		d = 7 * 24 * time.Hour
+	case "daily", "weekly":
 		pattern = "2006-01-02"
 	default:
-		d = 24 * time.Hour
+		interval = "daily"
 		pattern = "2006-01-02"
 	}
 
-	return &TimeBasedPolicy{
-		interval: d,
-		pattern:  pattern,
-		lastRoll: time.Now(),
+	p := &TimeBasedPolicy{
+		interval:  interval,
+		pattern:   pattern,
+		location:  time.Local,
+		weekStart: time.Monday,
+		modulate:  true,
+		origin:    time.Now(),
+	}
+	p.current = p.periodStart(time.Now())
+	return p
+}
+
+// WithModulate sets whether boundaries align to the calendar (default)
+// or are measured from when the policy was created
+func (p *TimeBasedPolicy) WithModulate(modulate bool) *TimeBasedPolicy {
+	p.modulate = modulate
+	p.current = p.periodStart(time.Now())
+	return p
+}
+
+// WithOffset shifts the boundaries, e.g. a 4h offset rolls daily at 04:00
+func (p *TimeBasedPolicy) WithOffset(offset time.Duration) *TimeBasedPolicy {
+	p.offset = offset
+	p.current = p.periodStart(time.Now())
+	return p
+}
+
+// length returns the nominal length of a period
+func (p *TimeBasedPolicy) length() time.Duration {
+	switch p.interval {
+	case "hourly":
+		return time.Hour
+	case "weekly":
+		return 7 * 24 * time.Hour
+	default:
+		return 24 * time.Hour
 	}
 }
 
+// WithLocation aligns boundaries to the given timezone (e.g. time.UTC)
+func (p *TimeBasedPolicy) WithLocation(loc *time.Location) *TimeBasedPolicy {
+	p.location = loc
+	p.current = p.periodStart(time.Now())
+	return p
+}
+
+// WithWeekStart sets the first day of the week for weekly rollover
+func (p *TimeBasedPolicy) WithWeekStart(day time.Weekday) *TimeBasedPolicy {
+	p.weekStart = day
+	p.current = p.periodStart(time.Now())
+	return p
+}
+
+// periodStart returns the start of the period containing t
+func (p *TimeBasedPolicy) periodStart(t time.Time) time.Time {
+	if !p.modulate {
+		start := p.origin.Add(p.offset)
+		if t.Before(start) {
+			return start.Add(-p.length())
+		}
+		return start.Add(t.Sub(start) / p.length() * p.length())
+	}
+
+	t = t.Add(-p.offset).In(p.location)
+	var start time.Time
+	switch p.interval {
+	case "hourly":
+		start = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, p.location)
+	case "weekly":
+		days := (int(t.Weekday()) - int(p.weekStart) + 7) % 7
+		start = time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, p.location)
+	default:
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, p.location)
+	}
+	return start.Add(p.offset)
+}
+
 // ShouldRoll implements RollingPolicy
 func (p *TimeBasedPolicy) ShouldRoll(entry *Entry, fileInfo os.FileInfo) bool {
-	return time.Since(p.lastRoll) >= p.interval
+	// A file left over from an earlier calendar period is rolled straight away
+	if !p.started {
+		p.started = true
+		if p.modulate && fileInfo != nil && fileInfo.Size() > 0 {
+			if start := p.periodStart(fileInfo.ModTime()); start.Before(p.current) {
+				p.ended = start
+				return true
+			}
+		}
+	}
+
+	start := p.periodStart(time.Now())
+	if start.After(p.current) {
+		p.ended = p.current
+		p.current = start
+		return true
+	}
+	return false
 }
 
 // GetNextFileName implements RollingPolicy
 func (p *TimeBasedPolicy) GetNextFileName(baseName string, index int) string {
 	ext := filepath.Ext(baseName)
 	name := baseName[:len(baseName)-len(ext)]
-	timestamp := time.Now().Format(p.pattern)
-	p.lastRoll = time.Now()
+	ended := p.ended
+	if ended.IsZero() {
+		ended = p.current
+	}
+	timestamp := ended.Format(p.pattern)
 	return fmt.Sprintf("%s.%s%s", name, timestamp, ext)
 }
 
@@ -149,6 +245,55 @@ func (p *CronBasedPolicy) GetNextFileName(baseName string, index int) string {
 	return fmt.Sprintf("%s.%s%s", name, timestamp, ext)
 }
 
+// OnStartupPolicy triggers a rollover the first time the appender checks
+// the existing file, so each process run starts with a fresh file
+type OnStartupPolicy struct {
+	minSize int64         // only roll if the file has at least this many bytes
+	minAge  time.Duration // only roll if the file was last written this long ago
+	checked bool
+}
+
+// NewOnStartupPolicy creates an on-startup policy that rolls non-empty files
+func NewOnStartupPolicy() *OnStartupPolicy {
+	return &OnStartupPolicy{minSize: 1}
+}
+
+// WithMinSize sets the minimum file size required to roll on startup
+func (p *OnStartupPolicy) WithMinSize(minBytes int64) *OnStartupPolicy {
+	p.minSize = minBytes
+	return p
+}
+
+// WithMinAge only rolls on startup if the file is older than age
+func (p *OnStartupPolicy) WithMinAge(age time.Duration) *OnStartupPolicy {
+	p.minAge = age
+	return p
+}
+
+// ShouldRoll implements RollingPolicy
+func (p *OnStartupPolicy) ShouldRoll(entry *Entry, fileInfo os.FileInfo) bool {
+	if p.checked {
+		return false
+	}
+	p.checked = true
+
+	if fileInfo == nil || fileInfo.Size() < p.minSize {
+		return false
+	}
+	if p.minAge > 0 && time.Since(fileInfo.ModTime()) < p.minAge {
+		return false
+	}
+	return true
+}
+
+// GetNextFileName implements RollingPolicy
+func (p *OnStartupPolicy) GetNextFileName(baseName string, index int) string {
+	ext := filepath.Ext(baseName)
+	name := baseName[:len(baseName)-len(ext)]
+	timestamp := time.Now().Format("2006-01-02-150405")
+	return fmt.Sprintf("%s.%s%s", name, timestamp, ext)
+}
+
 // CompositeTriggeringPolicy combines multiple policies (any triggers = roll)
 type CompositeTriggeringPolicy struct {
 	policies []RollingPolicy
@@ -159,26 +304,120 @@ func NewCompositeTriggeringPolicy(policies ...RollingPolicy) *CompositeTriggerin
 	return &CompositeTriggeringPolicy{policies: policies}
 }
 
-// ShouldRoll returns true if any policy triggers
+// ShouldRoll returns true if any policy triggers. All policies are asked
+// so stateful ones such as OnStartupPolicy are not skipped
 func (p *CompositeTriggeringPolicy) ShouldRoll(entry *Entry, fileInfo os.FileInfo) bool {
+	roll := false
 	for _, policy := range p.policies {
 		if policy.ShouldRoll(entry, fileInfo) {
-			return true
+			roll = true
 		}
 	}
-	return false
+	return roll
+}
+
+// RolloverStrategy moves the active file out of the way on rollover
+// and returns the name of the backup it produced. pattern is nil when
+// the appender has no FilePattern configured, started is when the active
+// file was started and dates the backup. A strategy may be shared by
+// several appenders and keeps no per-file state.
+type RolloverStrategy interface {
+	Rollover(filename string, pattern *FilePattern, started time.Time) (string, error)
+}
+
+// DefaultRolloverStrategy renames app.log to app-{min}.log, shifting
+// existing backups up by one and dropping the one at max (log4j2 compatible)
+type DefaultRolloverStrategy struct {
+	minIndex int
+	maxIndex int
+}
+
+// NewDefaultRolloverStrategy creates an index-based rollover strategy
+// keeping backups numbered from minIndex to maxIndex (inclusive)
+func NewDefaultRolloverStrategy(minIndex, maxIndex int) *DefaultRolloverStrategy {
+	if minIndex < 1 {
+		minIndex = 1
+	}
+	if maxIndex < minIndex {
+		maxIndex = minIndex
+	}
+	return &DefaultRolloverStrategy{minIndex: minIndex, maxIndex: maxIndex}
+}
+
+// indexedName returns the backup name for the given index, e.g. app-1.log,
+// t fills the date of a pattern
+func (s *DefaultRolloverStrategy) indexedName(filename string, pattern *FilePattern, index int, t time.Time) string {
+	if pattern != nil {
+		return pattern.Format(t, index)
+	}
+	ext := filepath.Ext(filename)
+	name := filename[:len(filename)-len(ext)]
+	return fmt.Sprintf("%s-%d%s", name, index, ext)
+}
+
+// Rollover implements RolloverStrategy
+func (s *DefaultRolloverStrategy) Rollover(filename string, pattern *FilePattern, started time.Time) (string, error) {
+	// Backups are dated with the period the active file was written in,
+	// like log4j2 does, so all names of one rollover share the same date
+	// and the shift finds the backups of earlier rollovers in that period
+	t := started
+
+	// Drop the oldest backup to make room
+	if err := os.Remove(s.indexedName(filename, pattern, s.maxIndex, t)); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	// Shift remaining backups up by one, highest first
+	for i := s.maxIndex - 1; i >= s.minIndex; i-- {
+		src := s.indexedName(filename, pattern, i, t)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := renameFile(src, s.indexedName(filename, pattern, i+1, t)); err != nil {
+			return "", err
+		}
+	}
+
+	target := s.indexedName(filename, pattern, s.minIndex, t)
+	if err := rotateFile(filename, target); err != nil {
+		return "", err
+	}
+	return target, nil
 }
 
 // RollingFileAppender writes logs with automatic file rotation
 type RollingFileAppender struct {
 	BaseAppender
+	banner
 	filename     string
 	file         *os.File
 	policies     []RollingPolicy
+	strategy     RolloverStrategy
+	pattern      *FilePattern
 	maxBackups   int           // max number of backup files to keep
+	backupsSet   bool          // maxBackups was set, not the default
+	fileStarted  time.Time     // when the active file was started, zero until the first rollover
 	maxAge       time.Duration // max age of backup files
 	totalMaxSize int64         // max total size of all log files
 	currentIndex int
+	indexLoaded  bool   // currentIndex was restored from existing backups
+	directWrite  bool   // write straight to pattern-named files, never rename
+	currentPath  string // path of the file currently open
+
+	wake    chan struct{} // capacity 1, tells the housekeeper jobs are queued
+	jobsMu  sync.Mutex
+	jobs    []func() // background compression/cleanup, guarded by jobsMu
+	pending sync.WaitGroup
+	rolls   uint64 // rollovers done, see settle
+	hooks   []func(oldPath, newPath string)
+
+	diskPolicy *DiskSpacePolicy
+
+	reopenInterval time.Duration // 0 disables external rotation checks
+	lastCheck      time.Time
+
+	symlink    string // link kept pointing at the active file
+	linkTarget string
 }
 
 // NewRollingFileAppender creates a rolling file appender
@@ -188,9 +427,10 @@ func NewRollingFileAppender(filename string) *RollingFileAppender {
 			name:   "RollingFile",
 			layout: NewTextLayout(),
 		},
-		filename:   filename,
-		maxBackups: 7,
-		policies:   make([]RollingPolicy, 0),
+		filename:       filename,
+		maxBackups:     7,
+		policies:       make([]RollingPolicy, 0),
+		reopenInterval: defaultReopenInterval,
 	}
 }
 
@@ -218,9 +458,95 @@ func (r *RollingFileAppender) WithPolicy(policy RollingPolicy) *RollingFileAppen
 	return r
 }
 
-// WithMaxBackups sets max number of backup files
+// WithStrategy sets the rollover strategy used to rename the active file
+func (r *RollingFileAppender) WithStrategy(strategy RolloverStrategy) *RollingFileAppender {
+	r.strategy = strategy
+	return r
+}
+
+// WithFilePattern sets the pattern used to name backup files
+// A pattern without a directory is resolved next to the active file
+func (r *RollingFileAppender) WithFilePattern(pattern *FilePattern) *RollingFileAppender {
+	r.pattern = pattern.relativeTo(filepath.Dir(r.filename))
+	return r
+}
+
+// FilePattern sets the backup file pattern (e.g. "app-%d{2006-01-02}-%i.log.gz")
+func (r *RollingFileAppender) FilePattern(pattern string) *RollingFileAppender {
+	return r.WithFilePattern(NewFilePattern(pattern))
+}
+
+// DirectWrite writes straight to the file named by the file pattern
+// (e.g. app-2024-05-01.log) and switches targets at the period boundary
+// instead of renaming, which is safer on Windows and NFS
+func (r *RollingFileAppender) DirectWrite(enable bool) *RollingFileAppender {
+	r.directWrite = enable
+	if enable && r.currentIndex == 0 {
+		r.currentIndex = 1
+	}
+	return r
+}
+
+// WithRolloverHook registers a function called after each rotation with the
+// path of the rotated (possibly compressed) backup and the new active file.
+// Hooks run on the housekeeping goroutine, after compression and cleanup.
+func (r *RollingFileAppender) WithRolloverHook(hook func(oldPath, newPath string)) *RollingFileAppender {
+	r.hooks = append(r.hooks, hook)
+	return r
+}
+
+// WithReopenCheck sets how often to check whether the active file was moved
+// away by an external tool (e.g. logrotate), 0 disables the check
+func (r *RollingFileAppender) WithReopenCheck(interval time.Duration) *RollingFileAppender {
+	r.reopenInterval = interval
+	return r
+}
+
+// WithSymlink keeps a symlink (e.g. "app-current.log") pointing at the
+// active file across rotations. A link without a directory is created next
+// to the log file. It is a no-op where symlinks are not supported.
+func (r *RollingFileAppender) WithSymlink(link string) *RollingFileAppender {
+	if filepath.Dir(link) == "." {
+		link = filepath.Join(filepath.Dir(r.filename), link)
+	}
+	r.symlink = link
+	return r
+}
+
+// WithHeader sets a function producing the header of each new file
+func (r *RollingFileAppender) WithHeader(header func() string) *RollingFileAppender {
+	r.header = header
+	return r
+}
+
+// WithFooter sets a function producing the footer written before a file
+// is rotated or closed
+func (r *RollingFileAppender) WithFooter(footer func() string) *RollingFileAppender {
+	r.footer = footer
+	return r
+}
+
+// Header sets the header text, expanding ${pid}, ${hostname}, ${time} and ${file}
+func (r *RollingFileAppender) Header(text string) *RollingFileAppender {
+	return r.WithHeader(bannerText(text, r.filename))
+}
+
+// Footer sets the footer text, expanding ${pid}, ${hostname}, ${time} and ${file}
+func (r *RollingFileAppender) Footer(text string) *RollingFileAppender {
+	return r.WithFooter(bannerText(text, r.filename))
+}
+
+// IndexRollover uses a DefaultRolloverStrategy bounded by min/max index
+func (r *RollingFileAppender) IndexRollover(minIndex, maxIndex int) *RollingFileAppender {
+	return r.WithStrategy(NewDefaultRolloverStrategy(minIndex, maxIndex))
+}
+
+// WithMaxBackups sets max number of backup files. Without it an index
+// strategy (WithStrategy, IndexRollover) keeps as many as its maxIndex
+// allows, otherwise 7 are kept.
 func (r *RollingFileAppender) WithMaxBackups(max int) *RollingFileAppender {
 	r.maxBackups = max
+	r.backupsSet = true
 	return r
 }
 
@@ -242,10 +568,9 @@ func (r *RollingFileAppender) Retention(durationStr string) *RollingFileAppender
 	return r
 }
 
-// MaxBackups sets max number of backup files
+// MaxBackups sets max number of backup files, see WithMaxBackups
 func (r *RollingFileAppender) MaxBackups(max int) *RollingFileAppender {
-	r.maxBackups = max
-	return r
+	return r.WithMaxBackups(max)
 }
 
 // SizePolicy adds a size-based triggering policy
@@ -259,6 +584,16 @@ func (r *RollingFileAppender) CronPolicy(schedule string) *RollingFileAppender {
 	return r.WithPolicy(NewCronBasedPolicy(schedule))
 }
 
+// TimePolicy adds a calendar-aligned time-based policy ("hourly", "daily", "weekly")
+func (r *RollingFileAppender) TimePolicy(interval string) *RollingFileAppender {
+	return r.WithPolicy(NewTimeBasedPolicy(interval))
+}
+
+// StartupPolicy adds an on-startup triggering policy
+func (r *RollingFileAppender) StartupPolicy() *RollingFileAppender {
+	return r.WithPolicy(NewOnStartupPolicy())
+}
+
 // FilterLevel sets a threshold filter for this appender
 func (r *RollingFileAppender) FilterLevel(level string) *RollingFileAppender {
 	return r.WithFilter(NewThresholdFilter(ParseLevel(level)))
@@ -285,42 +620,162 @@ func (r *RollingFileAppender) open() error {
 		return nil
 	}
 
+	if !r.indexLoaded {
+		r.indexLoaded = true
+		r.restoreIndex()
+	}
+
+	path := r.filename
+	if r.directWrite {
+		path = r.directPath(time.Now())
+	}
+
 	// Ensure directory exists
-	dir := filepath.Dir(r.filename)
+	dir := filepath.Dir(path)
 	if dir != "" && dir != "." {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return err
 		}
 	}
 
-	file, err := os.OpenFile(r.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return err
 	}
 	r.file = file
+	r.currentPath = path
+	r.lastCheck = time.Now()
+	r.writeHeader(file)
+
+	if r.symlink != "" && r.linkTarget != path {
+		if updateSymlink(path, r.symlink) == nil {
+			r.linkTarget = path
+		}
+	}
+	return nil
+}
+
+// updateSymlink atomically points link at target, using a relative
+// target so the directory can be moved as a whole
+func updateSymlink(target, link string) error {
+	if rel, err := filepath.Rel(filepath.Dir(link), target); err == nil {
+		target = rel
+	}
+	tmp := link + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return err
+	}
 	return nil
 }
 
+// reopen closes the active file and opens it again
+func (r *RollingFileAppender) reopen() error {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	return r.open()
+}
+
+// restoreIndex picks up the rollover index where a previous run left off,
+// so backups are not clobbered after a restart
+func (r *RollingFileAppender) restoreIndex() {
+	if r.directWrite {
+		// Resume the latest file of the current period, moving past any
+		// that were already compressed
+		now := time.Now()
+		current := r.directPath(now)
+		for r.pattern.HasIndex() {
+			next := r.pattern.Format(now, r.currentIndex+1)
+			done := r.pattern.Compressed() && fileExists(current+".gz")
+			if !done && !fileExists(next) && !fileExists(strings.TrimSuffix(next, ".gz")) {
+				break
+			}
+			r.currentIndex++
+			current = r.directPath(now)
+		}
+		return
+	}
+
+	base := filepath.Base(r.filename)
+	for _, b := range r.backups().list(r.filename) {
+		if index, ok := r.backupIndex(b.name, base); ok && index > r.currentIndex {
+			r.currentIndex = index
+		}
+	}
+}
+
+// backupIndex extracts the rollover index from a backup file name
+func (r *RollingFileAppender) backupIndex(name, base string) (int, bool) {
+	if r.pattern != nil {
+		if !r.pattern.HasIndex() {
+			return 0, false
+		}
+		return r.pattern.Match(name)
+	}
+
+	// app.log.3 or app.3.log
+	var digits string
+	if len(name) > len(base)+1 && name[:len(base)+1] == base+"." {
+		digits = name[len(base)+1:]
+	} else {
+		ext := filepath.Ext(base)
+		stem := base[:len(base)-len(ext)]
+		if len(name) > len(base)+1 && strings.HasPrefix(name, stem+".") && strings.HasSuffix(name, ext) {
+			digits = name[len(stem)+1 : len(name)-len(ext)]
+		}
+	}
+	if !isDigits(digits) {
+		return 0, false
+	}
+	index, err := strconv.Atoi(digits)
+	return index, err == nil
+}
+
+// directPath returns the file to write to at t in direct-write mode
+func (r *RollingFileAppender) directPath(t time.Time) string {
+	if r.pattern == nil {
+		// Derive app-%d{2006-01-02}.log from app.log
+		ext := filepath.Ext(r.filename)
+		name := r.filename[:len(r.filename)-len(ext)]
+		r.pattern = NewFilePattern(name + "-%d{2006-01-02}" + ext)
+	}
+	path := r.pattern.Format(t, r.currentIndex)
+	// The active file is plain text, it gets compressed once we move on
+	return strings.TrimSuffix(path, ".gz")
+}
+
 // shouldRoll checks if any policy triggers a rollover
 func (r *RollingFileAppender) shouldRoll(entry *Entry) bool {
 	if r.file == nil {
 		return false
 	}
+	// In direct-write mode the period boundary is reached once the
+	// pattern renders a different path
+	roll := r.directWrite && r.directPath(time.Now()) != r.currentPath
 	if len(r.policies) == 0 {
-		return false
+		return roll
 	}
 
 	fileInfo, err := r.file.Stat()
 	if err != nil {
-		return false
+		return roll
 	}
 
+	// Every policy sees every check: OnStartupPolicy and TimeBasedPolicy
+	// record their first look at the file, which must not be skipped
+	// because an earlier policy already triggered
 	for _, policy := range r.policies {
 		if policy.ShouldRoll(entry, fileInfo) {
-			return true
+			roll = true
 		}
 	}
-	return false
+	return roll
 }
 
 // rollover performs the file rotation
@@ -329,63 +784,391 @@ func (r *RollingFileAppender) rollover() error {
 		return nil
 	}
 
+	// Before the first rollover the file's last write stands in for its start
+	started := r.fileStarted
+	if started.IsZero() {
+		started = time.Now()
+		if info, err := r.file.Stat(); err == nil {
+			started = info.ModTime()
+		}
+	}
+
 	// Close current file
+	r.writeFooter(r.file)
 	r.file.Close()
 	r.file = nil
+	r.rolls++
 
-	// Determine new file name
-	r.currentIndex++
-	var newName string
-	if len(r.policies) > 0 {
-		newName = r.policies[0].GetNextFileName(r.filename, r.currentIndex)
+	if r.directWrite {
+		return r.switchFile()
+	}
+
+	// Backups may live in their own directory
+	if r.pattern != nil {
+		if err := os.MkdirAll(r.pattern.Dir(), 0755); err != nil {
+			r.open()
+			return err
+		}
+	}
+
+	// Strategy takes care of naming and bounding backups itself
+	var backup string
+	if r.strategy != nil {
+		var err error
+		if backup, err = r.strategy.Rollover(r.filename, r.pattern, started); err != nil {
+			r.open()
+			return err
+		}
+	} else {
+		// Determine new file name
+		r.currentIndex++
+		backup = r.nextFileName()
+
+		// Rename current to backup
+		if err := rotateFile(r.filename, backup); err != nil {
+			// If rename fails, try to reopen original
+			r.open()
+			return err
+		}
+	}
+
+	// Open new file, then compress and clean up old backups off the hot path
+	r.fileStarted = time.Now()
+	err := r.open()
+	set, hooks, current := r.backups(), r.hooks, r.filename
+	r.housekeep(func() {
+		set.compress(backup)
+		set.cleanup(current)
+		runHooks(hooks, backup, current)
+	})
+	return err
+}
+
+// switchFile moves on to the next pattern-named file in direct-write mode
+func (r *RollingFileAppender) switchFile() error {
+	previous := r.currentPath
+	if r.directPath(time.Now()) == previous {
+		// Same period, triggered by another policy (e.g. size)
+		if !r.pattern.HasIndex() {
+			return r.open()
+		}
+		r.currentIndex++
 	} else {
-		newName = fmt.Sprintf("%s.%d", r.filename, r.currentIndex)
+		r.currentIndex = 1
+	}
+
+	err := r.open()
+	set, hooks, current := r.backups(), r.hooks, r.currentPath
+	compressed := r.pattern.Compressed()
+	r.housekeep(func() {
+		rotated := previous
+		if compressed && compressFile(previous, previous+".gz") == nil {
+			rotated = previous + ".gz"
+		}
+		set.cleanup(current)
+		runHooks(hooks, rotated, current)
+	})
+	return err
+}
+
+// runHooks notifies rollover hooks
+func runHooks(hooks []func(oldPath, newPath string), oldPath, newPath string) {
+	for _, hook := range hooks {
+		hook(oldPath, newPath)
+	}
+}
+
+// housekeep queues a job for the background housekeeping worker,
+// starting the worker on first use. It never blocks: signals coalesce in
+// wake, so a slow compression backlog cannot stall Append.
+func (r *RollingFileAppender) housekeep(job func()) {
+	if r.wake == nil {
+		r.wake = make(chan struct{}, 1)
+		go r.housekeeper(r.wake)
+	}
+	r.pending.Add(1)
+	r.jobsMu.Lock()
+	r.jobs = append(r.jobs, job)
+	r.jobsMu.Unlock()
+	select {
+	case r.wake <- struct{}{}:
+	default: // already signaled, the worker picks the job up with the others
+	}
+}
+
+// housekeeper runs queued compression and cleanup jobs in order until
+// wake is closed
+func (r *RollingFileAppender) housekeeper(wake chan struct{}) {
+	for range wake {
+		r.jobsMu.Lock()
+		jobs := r.jobs
+		r.jobs = nil
+		r.jobsMu.Unlock()
+
+		for _, job := range jobs {
+			job()
+			r.pending.Done()
+		}
+	}
+}
+
+// settle waits for the queued compression and cleanup before a rollover
+// renames more files. r.mu is released meanwhile so writers are not held
+// up by gzip, false means another writer rolled the file or the appender
+// was closed in the meantime.
+func (r *RollingFileAppender) settle() bool {
+	if r.wake == nil {
+		return true
+	}
+	// Jobs run in order, so the others are done once this one ran
+	done := make(chan struct{})
+	r.housekeep(func() { close(done) })
+	rolls := r.rolls
+
+	r.mu.Unlock()
+	<-done
+	r.mu.Lock()
+	return r.rolls == rolls && r.file != nil
+}
+
+// nextFileName determines the backup name for the current index
+func (r *RollingFileAppender) nextFileName() string {
+	if r.pattern != nil {
+		name := r.pattern.Format(time.Now(), r.currentIndex)
+		// Skip indexes that are already taken rather than clobbering them
+		for r.pattern.HasIndex() && fileExists(name) {
+			r.currentIndex++
+			name = r.pattern.Format(time.Now(), r.currentIndex)
+		}
+		return name
+	}
+	if len(r.policies) > 0 {
+		return r.policies[0].GetNextFileName(r.filename, r.currentIndex)
+	}
+	return fmt.Sprintf("%s.%d", r.filename, r.currentIndex)
+}
+
+// compress gzips a backup in place if the file pattern asks for it
+func (s backupSet) compress(backup string) {
+	if s.pattern == nil || !s.pattern.Compressed() {
+		return
+	}
+	plain := strings.TrimSuffix(backup, ".gz")
+	if plain == backup {
+		return
+	}
+	if err := renameFile(backup, plain); err != nil {
+		return
+	}
+	if err := compressFile(plain, backup); err != nil {
+		// Keep the uncompressed backup rather than losing it
+		renameFile(plain, backup)
+	}
+}
+
+// compressFile writes a gzip copy of src to dst and removes src
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
 	}
+	defer in.Close()
 
-	// Rename current to backup
-	if err := os.Rename(r.filename, newName); err != nil {
-		// If rename fails, try to reopen original
-		r.open()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		gz.Close()
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		os.Remove(dst)
 		return err
 	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+
+	in.Close()
+	return os.Remove(src)
+}
 
-	// Clean up old backups
-	r.cleanup()
+// renameAttempts and renameBackoff bound how long a rename is retried,
+// covering the short windows where Windows antivirus or indexers hold files
+const (
+	renameAttempts = 5
+	renameBackoff  = 10 * time.Millisecond
+)
 
-	// Open new file
-	return r.open()
+// renameFile renames src to dst, retrying with a small backoff while the
+// file is held by another process on Windows. Other errors fail at once.
+func renameFile(src, dst string) error {
+	var err error
+	backoff := renameBackoff
+	for i := 0; i < renameAttempts; i++ {
+		if err = os.Rename(src, dst); err == nil {
+			return nil
+		}
+		if !isTransientRenameError(err) {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return err
+}
+
+// rotateFile moves the active log file to dst, falling back to copying it
+// and truncating the original when it cannot be renamed (e.g. it is held
+// open by another process on Windows)
+func rotateFile(src, dst string) error {
+	err := renameFile(src, dst)
+	if err == nil || os.IsNotExist(err) {
+		return err
+	}
+	return copyTruncate(src, dst)
 }
 
-// cleanup removes old backup files
-func (r *RollingFileAppender) cleanup() {
-	if r.maxBackups <= 0 && r.totalMaxSize <= 0 {
+// copyTruncate copies src to dst and truncates src
+func copyTruncate(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Truncate(src, 0)
+}
+
+// fileExists reports whether a path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// backupSet describes the backups of an appender and how many to keep.
+// Housekeeping jobs get a copy taken under the appender lock, so they
+// never read fields a setter or a rollover may change meanwhile.
+type backupSet struct {
+	filename     string
+	pattern      *FilePattern
+	dateLayouts  []string // see backupDateLayouts
+	maxBackups   int
+	maxAge       time.Duration
+	totalMaxSize int64
+}
+
+// backups returns the backup set of r, r.mu must be held
+func (r *RollingFileAppender) backups() backupSet {
+	maxBackups := r.maxBackups
+	if r.strategy != nil && !r.backupsSet {
+		// The strategy bounds the backups itself, the default count of
+		// 7 would cut it short
+		maxBackups = 0
+	}
+	return backupSet{
+		filename:     r.filename,
+		pattern:      r.pattern,
+		dateLayouts:  backupDateLayouts(r.policies),
+		maxBackups:   maxBackups,
+		maxAge:       r.maxAge,
+		totalMaxSize: r.totalMaxSize,
+	}
+}
+
+// cleanup removes old backup files, never touching the exclude path
+func (s backupSet) cleanup(exclude string) {
+	if s.maxBackups <= 0 && s.maxAge <= 0 && s.totalMaxSize <= 0 {
 		return
 	}
 
-	dir := filepath.Dir(r.filename)
-	base := filepath.Base(r.filename)
+	backups := s.list(exclude)
+
+	// Remove excess files by count
+	for len(backups) > s.maxBackups && s.maxBackups > 0 {
+		os.Remove(backups[0].path)
+		backups = backups[1:]
+	}
+
+	// Remove files by age
+	if s.maxAge > 0 {
+		expirationTime := time.Now().Add(-s.maxAge)
+		var validBackups []backupFile
+		for _, b := range backups {
+			if b.modTime.Before(expirationTime) {
+				os.Remove(b.path)
+			} else {
+				validBackups = append(validBackups, b)
+			}
+		}
+		backups = validBackups
+	}
+
+	// Remove files to stay under total size limit
+	if s.totalMaxSize > 0 {
+		var totalSize int64
+		for _, b := range backups {
+			totalSize += b.size
+		}
+		for totalSize > s.totalMaxSize && len(backups) > 0 {
+			totalSize -= backups[0].size
+			os.Remove(backups[0].path)
+			backups = backups[1:]
+		}
+	}
+}
+
+// backupFile describes a rotated log file on disk
+type backupFile struct {
+	name    string
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// list returns our backup files sorted oldest first, leaving out the
+// exclude path
+func (s backupSet) list(exclude string) []backupFile {
+	dir := filepath.Dir(s.filename)
+	base := filepath.Base(s.filename)
+	if s.pattern != nil {
+		dir = s.pattern.Dir()
+	}
 
 	files, err := os.ReadDir(dir)
 	if err != nil {
-		return
+		return nil
 	}
 
 	// Find matching backup files
-	type backupFile struct {
-		name    string
-		path    string
-		modTime time.Time
-		size    int64
-	}
 	var backups []backupFile
-
 	for _, f := range files {
-		if f.IsDir() {
+		if f.IsDir() || f.Type()&os.ModeSymlink != 0 {
 			continue
 		}
 		name := f.Name()
 		// Check if it's a backup of our log file
-		if len(name) > len(base) && name[:len(base)] == base {
+		if s.isBackup(name, base) && filepath.Join(dir, name) != filepath.Clean(exclude) {
 			info, err := f.Info()
 			if err != nil {
 				continue
@@ -403,39 +1186,73 @@ func (r *RollingFileAppender) cleanup() {
 	sort.Slice(backups, func(i, j int) bool {
 		return backups[i].modTime.Before(backups[j].modTime)
 	})
+	return backups
+}
 
-	// Remove excess files by count
-	for len(backups) > r.maxBackups && r.maxBackups > 0 {
-		os.Remove(backups[0].path)
-		backups = backups[1:]
+// isBackup reports whether a file name in the backup directory is ours
+func (s backupSet) isBackup(name, base string) bool {
+	if s.pattern != nil {
+		_, ok := s.pattern.Match(name)
+		return ok
+	}
+	// Only the names the policies and strategies produce, other files
+	// such as app.log.lock or app.error.log are left alone
+	name = strings.TrimSuffix(name, ".gz")
+	if index, ok := strings.CutPrefix(name, base+"."); ok {
+		return isDigits(index) // app.log.1
 	}
 
-	// Remove files by age
-	if r.maxAge > 0 {
-		expirationTime := time.Now().Add(-r.maxAge)
-		var validBackups []backupFile
-		for _, b := range backups {
-			if b.modTime.Before(expirationTime) {
-				os.Remove(b.path)
-			} else {
-				validBackups = append(validBackups, b)
+	ext := filepath.Ext(base)
+	middle, ok := strings.CutPrefix(name, base[:len(base)-len(ext)])
+	if !ok {
+		return false
+	}
+	if middle, ok = strings.CutSuffix(middle, ext); !ok || len(middle) < 2 {
+		return false
+	}
+	switch middle[0] {
+	case '-':
+		return isDigits(middle[1:]) // app-1.log
+	case '.':
+		if isDigits(middle[1:]) {
+			return true // app.1.log
+		}
+		for _, layout := range s.dateLayouts {
+			if _, err := time.Parse(layout, middle[1:]); err == nil {
+				return true // app.2024-05-01.log
 			}
 		}
-		backups = validBackups
 	}
+	return false
+}
 
-	// Remove files to stay under total size limit
-	if r.totalMaxSize > 0 {
-		var totalSize int64
-		for _, b := range backups {
-			totalSize += b.size
+// backupDateLayouts returns the date layouts policies name backups with
+func backupDateLayouts(policies []RollingPolicy) []string {
+	var layouts []string
+	for _, p := range policies {
+		switch p := p.(type) {
+		case *TimeBasedPolicy:
+			layouts = append(layouts, p.pattern)
+		case *CronBasedPolicy:
+			layouts = append(layouts, "2006-01-02")
+		case *OnStartupPolicy:
+			layouts = append(layouts, "2006-01-02-150405")
 		}
-		for totalSize > r.totalMaxSize && len(backups) > 0 {
-			totalSize -= backups[0].size
-			os.Remove(backups[0].path)
-			backups = backups[1:]
+	}
+	return layouts
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
 		}
 	}
+	return true
 }
 
 // Append writes a log entry
@@ -443,7 +1260,13 @@ func (r *RollingFileAppender) Append(entry *Entry) error {
 	if !r.applyFilter(entry) {
 		return nil
 	}
+	return r.appendUnfiltered(entry)
+}
 
+// appendUnfiltered writes entry regardless of the appender level and
+// filter, see AuditLogger. The disk policy still applies, it only drops
+// entries below INFO.
+func (r *RollingFileAppender) appendUnfiltered(entry *Entry) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -451,16 +1274,57 @@ func (r *RollingFileAppender) Append(entry *Entry) error {
 		return err
 	}
 
+	// Follow external rotation instead of writing to a moved/deleted file
+	if r.reopenInterval > 0 && time.Since(r.lastCheck) >= r.reopenInterval {
+		r.lastCheck = time.Now()
+		if fileMoved(r.file, r.currentPath) {
+			if err := r.reopen(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.diskPolicy != nil && !r.checkDiskSpace(entry) {
+		return nil
+	}
+
 	// Check if we need to roll
-	if r.shouldRoll(entry) {
+	if r.shouldRoll(entry) && r.settle() {
 		if err := r.rollover(); err != nil {
 			return err
 		}
 	}
 
-	data := r.layout.Format(entry)
-	_, err := r.file.Write(data)
-	return err
+	buf := formatEntry(r.layout, entry)
+	defer freeBuffer(buf)
+
+	data := buf.Bytes()
+	if _, err := r.file.Write(data); err != nil {
+		// The file may have been removed underneath us, reopen and retry once
+		if err := r.reopen(); err != nil {
+			return err
+		}
+		_, err = r.file.Write(data)
+		return err
+	}
+	return nil
+}
+
+// Reopen implements Reopener
+func (r *RollingFileAppender) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reopen()
+}
+
+// Sync commits the written entries to stable storage
+func (r *RollingFileAppender) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil {
+		return r.file.Sync()
+	}
+	return nil
 }
 
 // Close closes the file
@@ -468,7 +1332,15 @@ func (r *RollingFileAppender) Close() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	// Finish pending housekeeping, the worker restarts on the next rollover
+	if r.wake != nil {
+		close(r.wake)
+		r.wake = nil
+	}
+	r.pending.Wait()
+
 	if r.file != nil {
+		r.writeFooter(r.file)
 		err := r.file.Close()
 		r.file = nil
 		return err
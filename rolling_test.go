@@ -0,0 +1,254 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestFilePatternMatch checks that backup names round-trip through a pattern
+func TestFilePatternMatch(t *testing.T) {
+	date := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		pattern   string
+		index     int
+		formatted string
+		others    map[string]bool // name -> matches
+	}{
+		{"app-%i.log", 3, "app-3.log", map[string]bool{
+			"app-12.log": true, "app-x.log": false, "app.log": false,
+		}},
+		{"app-%d{2006-01-02}-%i.log.gz", 2, "app-2026-01-02-2.log.gz", map[string]bool{
+			"app-2026-13-40-1.log.gz": false, "app-2026-01-02-1.log": false,
+		}},
+		{"app-%d.log", 0, "app-2026-01-02.log", map[string]bool{
+			"app-2026-01-03.log": true, "app-today.log": false,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			p := NewFilePattern(tt.pattern)
+			name := p.Format(date, tt.index)
+			if name != tt.formatted {
+				t.Fatalf("Format = %q, want %q", name, tt.formatted)
+			}
+			if index, ok := p.Match(name); !ok || index != tt.index {
+				t.Errorf("Match(%q) = %d, %v, want %d, true", name, index, ok, tt.index)
+			}
+			for other, want := range tt.others {
+				if _, ok := p.Match(other); ok != want {
+					t.Errorf("Match(%q) = %v, want %v", other, ok, want)
+				}
+			}
+		})
+	}
+}
+
+// TestDefaultRolloverStrategy shifts backups up and drops the one past maxIndex
+func TestDefaultRolloverStrategy(t *testing.T) {
+	started := time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		pattern string // relative to the test directory, empty for none
+		max     int
+		rolls   int
+		backup  string            // name returned by every rollover
+		want    map[string]string // backup name -> content
+	}{
+		{"default names", "", 3, 4, "app-1.log", map[string]string{
+			"app-1.log": "4", "app-2.log": "3", "app-3.log": "2",
+		}},
+		{"dated pattern", "app-%d{2006-01-02}-%i.log", 2, 3, "app-2026-01-02-1.log", map[string]string{
+			"app-2026-01-02-1.log": "3", "app-2026-01-02-2.log": "2",
+		}},
+		{"single backup", "", 1, 2, "app-1.log", map[string]string{
+			"app-1.log": "2",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			filename := filepath.Join(dir, "app.log")
+			var pattern *FilePattern
+			if tt.pattern != "" {
+				pattern = NewFilePattern(filepath.Join(dir, tt.pattern))
+			}
+			s := NewDefaultRolloverStrategy(1, tt.max)
+
+			for i := 1; i <= tt.rolls; i++ {
+				if err := os.WriteFile(filename, []byte(strconv.Itoa(i)), 0644); err != nil {
+					t.Fatal(err)
+				}
+				backup, err := s.Rollover(filename, pattern, started)
+				if err != nil {
+					t.Fatalf("roll %d: %v", i, err)
+				}
+				if got := filepath.Base(backup); got != tt.backup {
+					t.Errorf("roll %d: backup %q, want %q", i, got, tt.backup)
+				}
+			}
+
+			got := readDir(t, dir, "app.log")
+			if len(got) != len(tt.want) {
+				t.Errorf("backups = %v, want %v", got, tt.want)
+			}
+			for name, content := range tt.want {
+				if got[name] != content {
+					t.Errorf("%s = %q, want %q", name, got[name], content)
+				}
+			}
+		})
+	}
+}
+
+// TestRestoreIndex resumes numbering after the highest existing backup
+func TestRestoreIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string // relative to the test directory, empty for none
+		files   []string
+		want    int
+	}{
+		{"no backups", "", nil, 0},
+		{"suffix index", "", []string{"app.log.3", "app.log.5", "app.log.lock"}, 5},
+		{"infix index", "", []string{"app.2.log", "app.error.log"}, 2},
+		{"pattern", "app-%i.log.gz", []string{"app-2.log.gz", "app-7.log.gz", "app-9.txt"}, 7},
+		{"pattern without index", "app-%d{2006-01-02}.log", []string{"app-2026-01-02.log"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			r := NewRollingFileAppender(filepath.Join(dir, "app.log"))
+			if tt.pattern != "" {
+				r.FilePattern(filepath.Join(dir, tt.pattern))
+			}
+			if err := r.open(); err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			if r.currentIndex != tt.want {
+				t.Errorf("currentIndex = %d, want %d", r.currentIndex, tt.want)
+			}
+		})
+	}
+}
+
+// TestRollingHousekeeperConcurrency rolls from many goroutines while
+// compression and cleanup run in the background, every job must run and
+// the strategy must stay within its bound
+func TestRollingHousekeeperConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string // relative to the test directory, empty for none
+		backups []string
+	}{
+		{"plain", "", []string{"app-1.log", "app-2.log", "app-3.log"}},
+		{"compressed", "app-%i.log.gz", []string{"app-1.log.gz", "app-2.log.gz", "app-3.log.gz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var hooks atomic.Int64
+			r := NewRollingFileAppender(filepath.Join(dir, "app.log")).
+				WithLayout(NewPatternLayout("%m%n")).
+				SizePolicy("1KB").
+				IndexRollover(1, 3).
+				WithRolloverHook(func(oldPath, newPath string) { hooks.Add(1) })
+			if tt.pattern != "" {
+				r.FilePattern(filepath.Join(dir, tt.pattern))
+			}
+
+			message := strings.Repeat("x", 99)
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 100; i++ {
+						if err := r.Append(&Entry{Time: time.Now(), Level: INFO, Message: message}); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if r.rolls == 0 {
+				t.Fatal("no rollover happened")
+			}
+			if got := hooks.Load(); got != int64(r.rolls) {
+				t.Errorf("hooks ran %d times for %d rollovers", got, r.rolls)
+			}
+
+			var names []string
+			for name := range readDir(t, dir, "app.log") {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if strings.Join(names, ",") != strings.Join(tt.backups, ",") {
+				t.Errorf("backups = %v, want %v", names, tt.backups)
+			}
+			if tt.pattern != "" {
+				for _, name := range names {
+					checkGzip(t, filepath.Join(dir, name))
+				}
+			}
+		})
+	}
+}
+
+// readDir returns the content of every file in dir except skip
+func readDir(t *testing.T, dir, skip string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, e := range entries {
+		if e.Name() == skip {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[e.Name()] = string(data)
+	}
+	return files
+}
+
+// checkGzip fails unless path is a readable gzip file
+func checkGzip(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Errorf("%s: %v", filepath.Base(path), err)
+		return
+	}
+	if _, err := io.Copy(io.Discard, zr); err != nil {
+		t.Errorf("%s: %v", filepath.Base(path), err)
+	}
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const samplerBuckets = 1024
+
+// Sampler limits repeated entries like zap's sampler: within each tick,
+// the first entries with a given level and message template are logged,
+// then only every thereafter-th. Counting is approximate, templates are
+// hashed into a fixed number of buckets. PANIC and FATAL are never sampled.
+type Sampler struct {
+	tick       int64
+	first      uint64
+	thereafter uint64
+	counters   [PANIC - TRACE][samplerBuckets]samplerCounter
+	dropped    atomic.Uint64
+}
+
+type samplerCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// NewSampler logs the first entries per template and level in each tick,
+// then every thereafter-th, 0 drops the rest of the tick
+func NewSampler(tick time.Duration, first, thereafter int) *Sampler {
+	if tick <= 0 {
+		tick = time.Second
+	}
+	return &Sampler{
+		tick:       int64(tick),
+		first:      uint64(max(first, 0)),
+		thereafter: uint64(max(thereafter, 0)),
+	}
+}
+
+// Dropped returns how many entries were sampled away
+func (s *Sampler) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// allow counts an entry and reports whether it should be logged
+func (s *Sampler) allow(level Level, template string) bool {
+	if level < TRACE || level >= PANIC {
+		return true
+	}
+	c := &s.counters[level-TRACE][fnv32a(template)%samplerBuckets]
+	n := c.incr(time.Now().UnixNano(), s.tick)
+	if n <= s.first || s.thereafter > 0 && (n-s.first)%s.thereafter == 0 {
+		return true
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+func (c *samplerCounter) incr(now, tick int64) uint64 {
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.count.Add(1)
+	}
+	if !c.resetAt.CompareAndSwap(resetAt, now+tick) {
+		// another goroutine started the new tick
+		return c.count.Add(1)
+	}
+	c.count.Store(1)
+	return 1
+}
+
+func fnv32a(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// SetSampler samples the entries of this logger and, unless they have
+// their own, of its registry children. nil disables sampling.
+func (l *Logger) SetSampler(s *Sampler) {
+	l.sampler.Store(s)
+}
+
+// Sampler returns the sampler set on this logger, nil if none
+func (l *Logger) Sampler() *Sampler {
+	return l.sampler.Load()
+}
+
+// sampled reports whether an entry passes the sampler in effect
+func (l *Logger) sampled(level Level, template string) bool {
+	for lg := l; lg != nil; lg = lg.parentLogger() {
+		if s := lg.sampler.Load(); s != nil {
+			return s.allow(level, template)
+		}
+		if !lg.named {
+			break
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// ReopenAll reopens the file-based appenders of the global logger and of
+// every logger from GetLogger, each appender once even when shared
+func ReopenAll() error {
+	loggers := make([]*Logger, 0, 1)
+	if l := global.Load(); l != nil {
+		loggers = append(loggers, l)
+	}
+	registryMu.Lock()
+	for _, l := range registry {
+		loggers = append(loggers, l)
+	}
+	registryMu.Unlock()
+
+	var errs []error
+	seen := make(map[Reopener]bool)
+	for _, l := range loggers {
+		for _, appender := range l.loadAppenders() {
+			r, ok := appender.(Reopener)
+			if !ok || seen[r] {
+				continue
+			}
+			seen[r] = true
+			if err := r.Reopen(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// errNoReopenSignal is returned by ReopenOnSignal on platforms without
+// SIGHUP when no signals are given
+var errNoReopenSignal = errors.New("reopen: no SIGHUP on this platform, pass the signals to watch")
+
+// ReopenOnSignal calls ReopenAll whenever one of sigs is received
+// (SIGHUP if none given), the standard contract for logrotate integration.
+// Call the returned function to stop listening. Platforms without SIGHUP,
+// such as Windows and js/wasm, need sigs and return an error otherwise.
+func ReopenOnSignal(sigs ...os.Signal) (stop func(), err error) {
+	if len(sigs) == 0 {
+		sigs = defaultReopenSignals
+	}
+	if len(sigs) == 0 {
+		return nil, errNoReopenSignal
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = ReopenAll()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}, nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package logger
+
+import "os"
+
+// defaultReopenSignals is empty where there is no SIGHUP to follow, so
+// ReopenOnSignal needs the signals passed explicitly
+var defaultReopenSignals []os.Signal
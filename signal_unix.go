@@ -0,0 +1,11 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultReopenSignals are watched by ReopenOnSignal when none are given
+var defaultReopenSignals = []os.Signal{syscall.SIGHUP}
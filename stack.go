@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StackTracer is implemented by errors that record where they were created,
+// as program counters returned by runtime.Callers. Errors from
+// github.com/pkg/errors and compatible libraries, whose StackTrace returns
+// a slice of uintptr-based frames, are recognized as well.
+type StackTracer interface {
+	StackTrace() []uintptr
+}
+
+// stackOf returns the stack recorded by err, if any
+func stackOf(err error) []uintptr {
+	if st, ok := err.(StackTracer); ok {
+		return st.StackTrace()
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	if t := m.Type().Out(0); t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	frames := m.Call(nil)[0]
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return pcs
+}
+
+// writeError renders err and every error it wraps, one per paragraph:
+//
+//	first error message
+//		at main.run(main.go:12)
+//	Caused by: wrapped error message
+//
+// maxFrames limits the frames printed per error, < 0 means unlimited
+func writeError(buf *bytes.Buffer, err error, maxFrames int) {
+	var last []uintptr
+	for i, e := range errorChain(err) {
+		if i > 0 {
+			buf.WriteString("Caused by: ")
+		}
+		buf.WriteString(e.Error())
+		buf.WriteByte('\n')
+		// wrappers often record the same stack as the error they wrap
+		if pcs := stackOf(e); len(pcs) > 0 && !sameStack(pcs, last) {
+			writeStack(buf, pcs, maxFrames)
+			last = pcs
+		}
+	}
+}
+
+// writeStack renders program counters as "\tat func(file:line)" lines
+func writeStack(buf *bytes.Buffer, pcs []uintptr, maxFrames int) {
+	if len(pcs) == 0 || maxFrames == 0 {
+		return
+	}
+	frames := runtime.CallersFrames(pcs)
+	written := 0
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" || frame.File != "" {
+			if maxFrames > 0 && written == maxFrames {
+				buf.WriteString("\t...\n")
+				return
+			}
+			buf.WriteString("\tat ")
+			buf.WriteString(frame.Function)
+			buf.WriteByte('(')
+			buf.WriteString(shortFile(frame.File))
+			buf.WriteByte(':')
+			buf.WriteString(strconv.Itoa(frame.Line))
+			buf.WriteString(")\n")
+			written++
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// captureStack records the calling goroutine's stack, skip counts frames
+// above the caller of captureStack
+func captureStack(skip int) []uintptr {
+	var pcs [64]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	stack := make([]uintptr, n)
+	copy(stack, pcs[:n])
+	return stack
+}
+
+// formatStack renders program counters as text, one "\tat" line per frame
+func formatStack(pcs []uintptr) string {
+	var buf bytes.Buffer
+	writeStack(&buf, pcs, -1)
+	return buf.String()
+}
+
+func sameStack(a, b []uintptr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// errorChain flattens err and everything it wraps, depth first.
+// Errors joined with errors.Join are visited in order.
+func errorChain(err error) []error {
+	var chain []error
+	var walk func(error)
+	walk = func(e error) {
+		for e != nil && len(chain) < 32 {
+			chain = append(chain, e)
+			switch u := e.(type) {
+			case interface{ Unwrap() []error }:
+				for _, inner := range u.Unwrap() {
+					walk(inner)
+				}
+				return
+			case interface{ Unwrap() error }:
+				e = u.Unwrap()
+			default:
+				return
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// shortFile strips the directory from a source file path
+func shortFile(file string) string {
+	if i := strings.LastIndexAny(file, `/\`); i >= 0 {
+		return file[i+1:]
+	}
+	return file
+}
@@ -0,0 +1,63 @@
+package logger
+
+import "time"
+
+// TimeTrack logs name at DEBUG and returns a func that logs it again at
+// INFO with the elapsed time in a "duration" field:
+//
+//	defer log.TimeTrack("load users")()
+func (l *Logger) TimeTrack(name string) func() {
+	start := time.Now()
+	l.logTyped(DEBUG, "", name+" started", nil)
+	return func() {
+		l.logTyped(INFO, "", name+" finished", []Field{Duration("duration", time.Since(start))})
+	}
+}
+
+// Stopwatch times the phases of an operation and logs them as one entry.
+// A Stopwatch is not safe for concurrent use.
+//
+//	sw := log.StartStopwatch("import")
+//	parse()
+//	sw.Lap("parse")
+//	store()
+//	sw.Lap("store")
+//	sw.Stop() // import finished duration=... parse=... store=...
+type Stopwatch struct {
+	logger *Logger
+	name   string
+	start  time.Time
+	last   time.Time
+	laps   []Field
+}
+
+// StartStopwatch starts a stopwatch logging to l
+func (l *Logger) StartStopwatch(name string) *Stopwatch {
+	now := time.Now()
+	return &Stopwatch{logger: l, name: name, start: now, last: now}
+}
+
+// Lap ends the current phase and returns its duration
+func (s *Stopwatch) Lap(phase string) time.Duration {
+	now := time.Now()
+	d := now.Sub(s.last)
+	s.last = now
+	s.laps = append(s.laps, Duration(phase, d))
+	return d
+}
+
+// Elapsed returns the time since the stopwatch started
+func (s *Stopwatch) Elapsed() time.Duration {
+	return time.Since(s.start)
+}
+
+// Stop logs the name at INFO with the total in a "duration" field and
+// one field per phase, and returns the total
+func (s *Stopwatch) Stop() time.Duration {
+	total := s.Elapsed()
+	fields := make([]Field, 0, len(s.laps)+1)
+	fields = append(fields, Duration("duration", total))
+	fields = append(fields, s.laps...)
+	s.logger.logTyped(INFO, "", s.name+" finished", fields)
+	return total
+}
@@ -0,0 +1,53 @@
+//go:build !logger_nodebug
+
+package logger
+
+// debugCompiled is false when the logger_nodebug tag removes DEBUG
+const debugCompiled = true
+
+func IsDebugEnabled() bool { return IsEnabled(DEBUG) }
+
+func Debug(format string, args ...interface{}) {
+	globalLogger().Debug(format, args...)
+}
+
+func Debugw(msg string, keysAndValues ...interface{}) {
+	globalLogger().Debugw(msg, keysAndValues...)
+}
+
+func Debugln(args ...interface{}) {
+	globalLogger().Debugln(args...)
+}
+
+// IsDebugEnabled checks if DEBUG is enabled
+func (l *Logger) IsDebugEnabled() bool { return l.IsEnabled(DEBUG) }
+
+// Debug logs at DEBUG level. format takes fmt verbs or, when it has no %,
+// SLF4J-style {} placeholders, see formatMessage
+func (l *Logger) Debug(format string, args ...interface{}) {
+	l.log(DEBUG, "", format, args...)
+}
+
+// Debugw logs a message with alternating keys and values at DEBUG level
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.logw(DEBUG, "", msg, keysAndValues)
+}
+
+// Debugln logs its arguments separated by spaces at DEBUG level
+func (l *Logger) Debugln(args ...interface{}) {
+	l.logln(DEBUG, true, args)
+}
+
+func (f *FieldLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	f.logw(DEBUG, msg, keysAndValues)
+}
+
+func (f *FieldLogger) Debug(format string, args ...interface{}) {
+	f.log(DEBUG, format, args...)
+}
+
+func (m *LimitedLogger) Debug(format string, args ...interface{}) {
+	if m.enabled(DEBUG) {
+		m.logger.log(DEBUG, "", format, args...)
+	}
+}
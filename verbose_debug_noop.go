@@ -0,0 +1,33 @@
+//go:build logger_nodebug
+
+package logger
+
+// Built with the logger_nodebug tag, the DEBUG methods do nothing and
+// IsDebugEnabled is false, so the compiler can inline them away. Arguments
+// are still evaluated, guard expensive ones with IsDebugEnabled.
+// IsEnabled and Check report DEBUG as disabled, so Log, SQL and the other
+// level-taking APIs drop it as well.
+
+const debugCompiled = false
+
+func IsDebugEnabled() bool { return false }
+
+func Debug(format string, args ...interface{}) {}
+
+func Debugw(msg string, keysAndValues ...interface{}) {}
+
+func Debugln(args ...interface{}) {}
+
+func (l *Logger) IsDebugEnabled() bool { return false }
+
+func (l *Logger) Debug(format string, args ...interface{}) {}
+
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {}
+
+func (l *Logger) Debugln(args ...interface{}) {}
+
+func (f *FieldLogger) Debugw(msg string, keysAndValues ...interface{}) {}
+
+func (f *FieldLogger) Debug(format string, args ...interface{}) {}
+
+func (m *LimitedLogger) Debug(format string, args ...interface{}) {}
@@ -0,0 +1,52 @@
+//go:build !logger_notrace && !logger_nodebug
+
+package logger
+
+// traceCompiled is false when a build tag removes the TRACE level
+const traceCompiled = true
+
+func IsTraceEnabled() bool { return IsEnabled(TRACE) }
+
+func Trace(format string, args ...interface{}) {
+	globalLogger().Trace(format, args...)
+}
+
+func Tracew(msg string, keysAndValues ...interface{}) {
+	globalLogger().Tracew(msg, keysAndValues...)
+}
+
+func Traceln(args ...interface{}) {
+	globalLogger().Traceln(args...)
+}
+
+// IsTraceEnabled checks if TRACE is enabled
+func (l *Logger) IsTraceEnabled() bool { return l.IsEnabled(TRACE) }
+
+// Trace logs at TRACE level
+func (l *Logger) Trace(format string, args ...interface{}) {
+	l.log(TRACE, "", format, args...)
+}
+
+// Tracew logs a message with alternating keys and values at TRACE level
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {
+	l.logw(TRACE, "", msg, keysAndValues)
+}
+
+// Traceln logs its arguments separated by spaces at TRACE level
+func (l *Logger) Traceln(args ...interface{}) {
+	l.logln(TRACE, true, args)
+}
+
+func (f *FieldLogger) Tracew(msg string, keysAndValues ...interface{}) {
+	f.logw(TRACE, msg, keysAndValues)
+}
+
+func (f *FieldLogger) Trace(format string, args ...interface{}) {
+	f.log(TRACE, format, args...)
+}
+
+func (m *LimitedLogger) Trace(format string, args ...interface{}) {
+	if m.enabled(TRACE) {
+		m.logger.log(TRACE, "", format, args...)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build logger_notrace || logger_nodebug
+
+package logger
+
+// Built with the logger_notrace or logger_nodebug tag, the TRACE methods do
+// nothing and IsTraceEnabled is false, so the compiler can inline them away.
+// Arguments are still evaluated, guard expensive ones with IsTraceEnabled.
+// IsEnabled and Check report TRACE as disabled, so Log, SQL and the other
+// level-taking APIs drop it as well.
+
+const traceCompiled = false
+
+func IsTraceEnabled() bool { return false }
+
+func Trace(format string, args ...interface{}) {}
+
+func Tracew(msg string, keysAndValues ...interface{}) {}
+
+func Traceln(args ...interface{}) {}
+
+func (l *Logger) IsTraceEnabled() bool { return false }
+
+func (l *Logger) Trace(format string, args ...interface{}) {}
+
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {}
+
+func (l *Logger) Traceln(args ...interface{}) {}
+
+func (f *FieldLogger) Tracew(msg string, keysAndValues ...interface{}) {}
+
+func (f *FieldLogger) Trace(format string, args ...interface{}) {}
+
+func (m *LimitedLogger) Trace(format string, args ...interface{}) {}